@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/morph/internal/app"
 )
 
 const port = 8080
 
+// shutdownTimeout is how long requests in flight get to finish on shutdown.
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	log.Println("Starting morph on port", port)
 
@@ -17,9 +26,48 @@ func main() {
 	http.HandleFunc("/monoHandler", app.MonoHandler)
 	http.HandleFunc("/monoWebHook", app.MonoWebHook)
 	http.HandleFunc("/sendMessage", app.SendMessage)
+	http.HandleFunc("/editMessage", app.EditMessage)
+	http.HandleFunc("/deleteMessage", app.DeleteMessage)
 	http.HandleFunc("/notificationHandler", app.NotificationHandler)
+	http.HandleFunc("/mccReport", app.MCCReport)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: fmt.Sprintf(":%d", port)}
+
+	// With polling on, Telegram updates are fetched instead of pushed to
+	// /cashHandler, so the bot works without a public URL.
+	polled := make(chan struct{})
+	if os.Getenv("MORPH_TELEGRAM_POLLING") == "on" {
+		go func() {
+			defer close(polled)
+			if err := app.PollUpdates(ctx); err != nil {
+				log.Printf("Polling stopped: %s", err)
+				stop()
+			}
+		}()
+	} else {
+		close(polled)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		log.Println("Shutting down morph...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down server: %s", err)
+		}
+	}()
 
-	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), nil); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Error starting server: %s", err)
 	}
+	// Let requests in flight and the update being handled finish, so its
+	// offset is saved.
+	<-stopped
+	<-polled
 }
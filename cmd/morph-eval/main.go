@@ -0,0 +1,58 @@
+// Command morph-eval runs a golden dataset through the classification path
+// and reports the accuracy, optionally compared with a previous run.
+//
+//	go run ./cmd/morph-eval -dataset eval/golden.jsonl -out eval/last.json -previous eval/baseline.json
+//
+// The AI provider is configured with the same MORPH_AI_* variables as the
+// functions, or with -provider.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/morph/internal/app"
+	"github.com/morph/internal/eval"
+)
+
+func main() {
+	datasetPath := flag.String("dataset", "eval/golden.jsonl", "JSONL dataset of samples with the expected category and subcategory")
+	outPath := flag.String("out", "", "file to save this run to, for later comparison")
+	previousPath := flag.String("previous", "", "run saved with -out to compare with")
+	provider := flag.String("provider", "", "AI provider, overriding MORPH_AI_PROVIDER (openai, openai-compatible, anthropic, offline)")
+	flag.Parse()
+
+	if *provider != "" {
+		os.Setenv("MORPH_AI_PROVIDER", *provider)
+	}
+	app.SetAIService(app.NewAIProvider())
+
+	file, err := os.Open(*datasetPath)
+	if err != nil {
+		log.Fatalf("Could not open dataset: %v", err)
+	}
+	cases, err := eval.ReadDataset(file)
+	file.Close()
+	if err != nil {
+		log.Fatalf("Could not read dataset: %v", err)
+	}
+
+	run := eval.Evaluate(context.Background(), cases, app.Classify)
+	eval.WriteReport(os.Stdout, run)
+
+	if *previousPath != "" {
+		previous, err := eval.LoadRun(*previousPath)
+		if err != nil {
+			log.Fatalf("Could not load previous run: %v", err)
+		}
+		eval.WriteDiff(os.Stdout, previous, run)
+	}
+
+	if *outPath != "" {
+		if err := eval.SaveRun(*outPath, run); err != nil {
+			log.Fatalf("Could not save run: %v", err)
+		}
+	}
+}
@@ -12,7 +12,10 @@ func init() {
 	functions.HTTP("monoHandler", monoHandler)
 	functions.HTTP("monoWebHook", monoWebHook)
 	functions.HTTP("sendMessage", sendMessage)
+	functions.HTTP("editMessage", editMessage)
+	functions.HTTP("deleteMessage", deleteMessage)
 	functions.HTTP("notificationHandler", notificationHandler)
+	functions.HTTP("mccReport", mccReport)
 }
 
 func cashHandler(w http.ResponseWriter, r *http.Request) {
@@ -31,6 +34,18 @@ func sendMessage(w http.ResponseWriter, r *http.Request) {
 	app.SendMessage(w, r)
 }
 
+func editMessage(w http.ResponseWriter, r *http.Request) {
+	app.EditMessage(w, r)
+}
+
+func deleteMessage(w http.ResponseWriter, r *http.Request) {
+	app.DeleteMessage(w, r)
+}
+
 func notificationHandler(w http.ResponseWriter, r *http.Request) {
 	app.NotificationHandler(w, r)
 }
+
+func mccReport(w http.ResponseWriter, r *http.Request) {
+	app.MCCReport(w, r)
+}
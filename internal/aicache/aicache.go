@@ -0,0 +1,135 @@
+package aicache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/storage"
+)
+
+// Entry is a cached classification.
+type Entry struct {
+	Response  aiservice.Response `json:"response"`
+	ExpiresAt int64              `json:"expiresAt"`
+}
+
+// Backend stores cache entries.
+type Backend interface {
+	Get(key string) (Entry, bool)
+	Set(key string, entry Entry)
+}
+
+// Cache wraps an AIService and serves repeated prompts from the backend.
+// Keys combine the taxonomy version with the normalized prompts, so editing
+// the categories invalidates every entry.
+type Cache struct {
+	service aiservice.AIService
+	backend Backend
+	ttl     time.Duration
+	version func() string
+	now     func() time.Time
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+func New(service aiservice.AIService, backend Backend, ttl time.Duration, version func() string) *Cache {
+	return &Cache{
+		service: service,
+		backend: backend,
+		ttl:     ttl,
+		version: version,
+		now:     time.Now,
+	}
+}
+
+// normalize makes prompts that differ only in case or spacing share a key.
+func normalize(prompt string) string {
+	return strings.Join(strings.Fields(strings.ToLower(prompt)), " ")
+}
+
+func (cache *Cache) key(name string, systemPrompt string, userPrompt string) string {
+	sum := sha256.Sum256([]byte(cache.version() + "\n" + name + "\n" + normalize(systemPrompt) + "\n" + normalize(userPrompt)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (cache *Cache) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	key := cache.key(name, systemPrompt, userPrompt)
+
+	if entry, ok := cache.backend.Get(key); ok && cache.now().Unix() < entry.ExpiresAt {
+		hits := cache.hits.Add(1)
+		log.Printf("[AICache] Hit (hits: %d, misses: %d)", hits, cache.misses.Load())
+		response := entry.Response
+		return &response, nil
+	}
+
+	misses := cache.misses.Add(1)
+	log.Printf("[AICache] Miss (hits: %d, misses: %d)", cache.hits.Load(), misses)
+
+	response, err := cache.service.Request(name, description, systemPrompt, userPrompt, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.backend.Set(key, Entry{
+		Response:  *response,
+		ExpiresAt: cache.now().Add(cache.ttl).Unix(),
+	})
+	return response, nil
+}
+
+// RequestImage passes image requests through uncached: every photo is
+// different, so they would never hit.
+func (cache *Cache) RequestImage(name string, description string, systemPrompt string, userPrompt string, image aiservice.Image, ctx *context.Context) (*aiservice.Response, error) {
+	return aiservice.RequestImage(cache.service, name, description, systemPrompt, userPrompt, image, ctx)
+}
+
+// MemoryBackend keeps entries for the lifetime of the instance.
+type MemoryBackend struct {
+	mutex   sync.Mutex
+	entries map[string]Entry
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{entries: map[string]Entry{}}
+}
+
+func (backend *MemoryBackend) Get(key string) (Entry, bool) {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	entry, ok := backend.entries[key]
+	return entry, ok
+}
+
+func (backend *MemoryBackend) Set(key string, entry Entry) {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	backend.entries[key] = entry
+}
+
+// StorageBackend persists entries through storage.Storage, one key per entry.
+type StorageBackend struct {
+	Storage storage.Storage
+}
+
+func (backend StorageBackend) Get(key string) (Entry, bool) {
+	var entry Entry
+	found, err := backend.Storage.Load("aicache_"+key, &entry)
+	if err != nil {
+		log.Printf("[AICache] Could not load entry: %v", err)
+		return Entry{}, false
+	}
+	return entry, found
+}
+
+func (backend StorageBackend) Set(key string, entry Entry) {
+	if err := backend.Storage.Save("aicache_"+key, entry); err != nil {
+		log.Printf("[AICache] Could not save entry: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package aicache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/third_party/filestore"
+)
+
+type countingAI struct {
+	response *aiservice.Response
+	calls    int
+}
+
+func (a *countingAI) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	a.calls++
+	if a.response == nil {
+		return nil, aiservice.ErrEmptyChoices
+	}
+	return a.response, nil
+}
+
+func TestCache_ServesNormalizedRepeatsFromBackend(t *testing.T) {
+	ai := &countingAI{response: &aiservice.Response{Category: "Food", Subcategory: "Shop", Amount: 12}}
+	cache := New(ai, NewMemoryBackend(), time.Hour, func() string { return "v1" })
+	ctx := context.Background()
+
+	first, _ := cache.Request("Morph", "desc", "system", "Coffee  12", &ctx)
+	second, _ := cache.Request("Morph", "desc", "system", " coffee 12 ", &ctx)
+
+	if ai.calls != 1 {
+		t.Fatalf("Expected 1 AI call, got %d", ai.calls)
+	}
+	if first == nil || second == nil || second.Category != first.Category || second.Amount != first.Amount {
+		t.Errorf("Expected cached response %+v, got %+v", first, second)
+	}
+	if cache.hits.Load() != 1 || cache.misses.Load() != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %d/%d", cache.hits.Load(), cache.misses.Load())
+	}
+}
+
+func TestCache_ExpiresAfterTTL(t *testing.T) {
+	ai := &countingAI{response: &aiservice.Response{Category: "Food"}}
+	cache := New(ai, NewMemoryBackend(), time.Hour, func() string { return "v1" })
+	now := time.Unix(1746194127, 0)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	cache.Request("Morph", "desc", "system", "coffee", &ctx)
+	now = now.Add(2 * time.Hour)
+	cache.Request("Morph", "desc", "system", "coffee", &ctx)
+
+	if ai.calls != 2 {
+		t.Errorf("Expected 2 AI calls after expiry, got %d", ai.calls)
+	}
+}
+
+func TestCache_TaxonomyChangeInvalidates(t *testing.T) {
+	ai := &countingAI{response: &aiservice.Response{Category: "Food"}}
+	version := "v1"
+	cache := New(ai, NewMemoryBackend(), time.Hour, func() string { return version })
+	ctx := context.Background()
+
+	cache.Request("Morph", "desc", "system", "coffee", &ctx)
+	version = "v2"
+	cache.Request("Morph", "desc", "system", "coffee", &ctx)
+
+	if ai.calls != 2 {
+		t.Errorf("Expected 2 AI calls after taxonomy change, got %d", ai.calls)
+	}
+}
+
+func TestCache_DoesNotStoreFailures(t *testing.T) {
+	ai := &countingAI{}
+	cache := New(ai, NewMemoryBackend(), time.Hour, func() string { return "v1" })
+	ctx := context.Background()
+
+	if _, err := cache.Request("Morph", "desc", "system", "coffee", &ctx); !errors.Is(err, aiservice.ErrEmptyChoices) {
+		t.Fatalf("Expected ErrEmptyChoices, got %v", err)
+	}
+	cache.Request("Morph", "desc", "system", "coffee", &ctx)
+
+	if ai.calls != 2 {
+		t.Errorf("Expected 2 AI calls, got %d", ai.calls)
+	}
+}
+
+func TestStorageBackend_PersistsEntries(t *testing.T) {
+	t.Setenv("MORPH_STORAGE_DIR", t.TempDir())
+	backend := StorageBackend{Storage: filestore.FileStore{}}
+
+	if _, ok := backend.Get("missing"); ok {
+		t.Fatal("Expected missing entry")
+	}
+
+	entry := Entry{Response: aiservice.Response{Category: "Food", Subcategory: "Shop", Amount: 5}, ExpiresAt: 42}
+	backend.Set("key", entry)
+
+	got, ok := backend.Get("key")
+	if !ok {
+		t.Fatal("Expected stored entry")
+	}
+	if got.Response.Category != "Food" || got.ExpiresAt != 42 {
+		t.Errorf("Expected %+v, got %+v", entry, got)
+	}
+}
+
+func TestCache_ImageRequestsNeedVisionService(t *testing.T) {
+	cache := New(&countingAI{}, NewMemoryBackend(), time.Hour, func() string { return "v1" })
+	ctx := context.Background()
+
+	if _, err := cache.RequestImage("Morph", "desc", "system", "receipt", aiservice.Image{}, &ctx); !errors.Is(err, aiservice.ErrVisionUnsupported) {
+		t.Errorf("Expected ErrVisionUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+// Package airecord records AI classifications to fixture files and replays
+// them, so changes around the prompts can be checked against real model
+// answers without network access.
+package airecord
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/morph/internal/aiservice"
+)
+
+// ErrUnknownPrompt is returned by Replayer for a prompt that was never recorded.
+var ErrUnknownPrompt = errors.New("no recorded response for prompt")
+
+// Fixture is a recorded prompt and the response it got. The prompts are kept
+// so a fixture can be read, and reviewed, on its own.
+type Fixture struct {
+	Key      string             `json:"key"`
+	Name     string             `json:"name"`
+	System   string             `json:"system"`
+	User     string             `json:"user"`
+	Image    string             `json:"image,omitempty"`
+	Response aiservice.Response `json:"response"`
+}
+
+// Key hashes a prompt. Unlike the classification cache it uses the prompts
+// verbatim, so any change to what the model would see misses.
+func Key(name string, systemPrompt string, userPrompt string, image *aiservice.Image) string {
+	hash := sha256.New()
+	hash.Write([]byte(name + "\n" + systemPrompt + "\n" + userPrompt))
+	if image != nil {
+		hash.Write([]byte("\n" + imageHash(*image)))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func imageHash(image aiservice.Image) string {
+	sum := sha256.Sum256(image.Data)
+	return image.MediaType + ":" + hex.EncodeToString(sum[:])
+}
+
+// path is where the fixture for a prompt lives: the request name and a
+// prefix of its key, e.g. "Morph-3fa29c1e0b7d4a18.json". The handlers name
+// every request "Morph", so the key alone tells the fixtures apart; open one
+// to see which template its prompts came from.
+func path(dir string, name string, key string) string {
+	return filepath.Join(dir, name+"-"+key[:16]+".json")
+}
+
+// Recorder wraps an AIService and writes every successful response to a
+// fixture in Dir.
+type Recorder struct {
+	Service aiservice.AIService
+	Dir     string
+	mutex   sync.Mutex
+}
+
+func (recorder *Recorder) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	response, err := recorder.Service.Request(name, description, systemPrompt, userPrompt, ctx)
+	if err == nil {
+		recorder.save(Fixture{Key: Key(name, systemPrompt, userPrompt, nil), Name: name, System: systemPrompt, User: userPrompt, Response: *response})
+	}
+	return response, err
+}
+
+func (recorder *Recorder) RequestImage(name string, description string, systemPrompt string, userPrompt string, image aiservice.Image, ctx *context.Context) (*aiservice.Response, error) {
+	response, err := aiservice.RequestImage(recorder.Service, name, description, systemPrompt, userPrompt, image, ctx)
+	if err == nil {
+		recorder.save(Fixture{Key: Key(name, systemPrompt, userPrompt, &image), Name: name, System: systemPrompt, User: userPrompt, Image: imageHash(image), Response: *response})
+	}
+	return response, err
+}
+
+// save writes fixture, logging rather than failing: recording must never
+// break the classification it records.
+func (recorder *Recorder) save(fixture Fixture) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		log.Printf("[AIRecord] Could not encode fixture: %v", err)
+		return
+	}
+	if err := os.MkdirAll(recorder.Dir, 0o755); err != nil {
+		log.Printf("[AIRecord] Could not create %s: %v", recorder.Dir, err)
+		return
+	}
+	file := path(recorder.Dir, fixture.Name, fixture.Key)
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		log.Printf("[AIRecord] Could not write %s: %v", file, err)
+		return
+	}
+	log.Printf("[AIRecord] Recorded %s", file)
+}
+
+// Replayer serves the responses recorded in Dir and fails with
+// ErrUnknownPrompt for anything else.
+type Replayer struct {
+	Dir string
+}
+
+func (replayer Replayer) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	return replayer.load(name, Key(name, systemPrompt, userPrompt, nil))
+}
+
+func (replayer Replayer) RequestImage(name string, description string, systemPrompt string, userPrompt string, image aiservice.Image, ctx *context.Context) (*aiservice.Response, error) {
+	return replayer.load(name, Key(name, systemPrompt, userPrompt, &image))
+}
+
+func (replayer Replayer) load(name string, key string) (*aiservice.Response, error) {
+	file := path(replayer.Dir, name, key)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("[AIRecord] No fixture for %s prompt %s; record it again if the prompt changed on purpose", name, key)
+		return nil, fmt.Errorf("%w: %s %s", ErrUnknownPrompt, name, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("could not decode %s: %w", file, err)
+	}
+	// The file name only holds a prefix of the key.
+	if fixture.Key != key {
+		return nil, fmt.Errorf("%w: %s %s", ErrUnknownPrompt, name, key)
+	}
+	return &fixture.Response, nil
+}
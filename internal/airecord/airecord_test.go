@@ -0,0 +1,105 @@
+package airecord
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+)
+
+type countingAI struct {
+	response *aiservice.Response
+	calls    int
+}
+
+func (a *countingAI) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	a.calls++
+	if a.response == nil {
+		return nil, aiservice.ErrEmptyChoices
+	}
+	return a.response, nil
+}
+
+func (a *countingAI) RequestImage(name string, description string, systemPrompt string, userPrompt string, image aiservice.Image, ctx *context.Context) (*aiservice.Response, error) {
+	return a.Request(name, description, systemPrompt, userPrompt, ctx)
+}
+
+func TestRecorder_RecordedResponsesAreReplayed(t *testing.T) {
+	dir := t.TempDir()
+	ai := &countingAI{response: &aiservice.Response{Category: "Food", Subcategory: "Shop", Amount: 12}}
+	recorder := &Recorder{Service: ai, Dir: dir}
+	ctx := context.Background()
+
+	if _, err := recorder.Request("cash", "desc", "system", "coffee 12", &ctx); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "cash-*.json"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 fixture, got %v", files)
+	}
+
+	replayed, err := Replayer{Dir: dir}.Request("cash", "other desc", "system", "coffee 12", &ctx)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if replayed.Category != "Food" || replayed.Subcategory != "Shop" || replayed.Amount != 12 {
+		t.Errorf("Expected the recorded response, got %+v", replayed)
+	}
+}
+
+func TestRecorder_ErrorsAreNotRecorded(t *testing.T) {
+	dir := t.TempDir()
+	recorder := &Recorder{Service: &countingAI{}, Dir: dir}
+	ctx := context.Background()
+
+	if _, err := recorder.Request("cash", "desc", "system", "coffee", &ctx); !errors.Is(err, aiservice.ErrEmptyChoices) {
+		t.Fatalf("Expected the service error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no fixtures, got %d", len(entries))
+	}
+}
+
+func TestReplayer_UnknownPromptFails(t *testing.T) {
+	dir := t.TempDir()
+	recorder := &Recorder{Service: &countingAI{response: &aiservice.Response{Category: "Food"}}, Dir: dir}
+	ctx := context.Background()
+	recorder.Request("cash", "desc", "system", "coffee 12", &ctx)
+
+	replayer := Replayer{Dir: dir}
+	for _, prompt := range []string{"coffee  12", "coffee 13"} {
+		if _, err := replayer.Request("cash", "desc", "system", prompt, &ctx); !errors.Is(err, ErrUnknownPrompt) {
+			t.Errorf("Expected ErrUnknownPrompt for %q, got %v", prompt, err)
+		}
+	}
+	if _, err := replayer.Request("cash", "desc", "system v2", "coffee 12", &ctx); !errors.Is(err, ErrUnknownPrompt) {
+		t.Errorf("Expected ErrUnknownPrompt for a changed system prompt, got %v", err)
+	}
+}
+
+func TestReplayer_ImagesAreKeyedByContent(t *testing.T) {
+	dir := t.TempDir()
+	recorder := &Recorder{Service: &countingAI{response: &aiservice.Response{Category: "Food", Merchant: "Silpo"}}, Dir: dir}
+	ctx := context.Background()
+	receipt := aiservice.Image{Data: []byte("receipt"), MediaType: "image/jpeg"}
+
+	if _, err := recorder.RequestImage("receipt", "desc", "system", "read it", receipt, &ctx); err != nil {
+		t.Fatalf("RequestImage failed: %v", err)
+	}
+
+	replayer := Replayer{Dir: dir}
+	replayed, err := replayer.RequestImage("receipt", "desc", "system", "read it", receipt, &ctx)
+	if err != nil || replayed.Merchant != "Silpo" {
+		t.Fatalf("Expected the recorded receipt, got %+v, %v", replayed, err)
+	}
+	other := aiservice.Image{Data: []byte("another receipt"), MediaType: "image/jpeg"}
+	if _, err := replayer.RequestImage("receipt", "desc", "system", "read it", other, &ctx); !errors.Is(err, ErrUnknownPrompt) {
+		t.Errorf("Expected ErrUnknownPrompt for another image, got %v", err)
+	}
+	if _, err := replayer.Request("receipt", "desc", "system", "read it", &ctx); !errors.Is(err, ErrUnknownPrompt) {
+		t.Errorf("Expected ErrUnknownPrompt without the image, got %v", err)
+	}
+}
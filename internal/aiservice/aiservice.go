@@ -2,13 +2,83 @@ package aiservice
 
 import "context"
 
+// Alternative is another category/subcategory pair the model considered.
+type Alternative struct {
+	Category    string `json:"category"`
+	Subcategory string `json:"subcategory"`
+}
+
+// Expense is one of several expenses found in a single input.
+type Expense struct {
+	Category     string        `json:"category"`
+	Subcategory  string        `json:"subcategory"`
+	Amount       float64       `json:"amount"`
+	Currency     string        `json:"currency"`
+	Account      string        `json:"account"`
+	Date         string        `json:"date"`
+	Confidence   float64       `json:"confidence"`
+	Alternatives []Alternative `json:"alternatives"`
+}
+
 type Response struct {
-	Category      string  `json:"category"`
-	Subcategory   string  `json:"subcategory"`
-	Amount        float64 `json:"amount"`
-	IsTransaction bool    `json:"isTransaction"`
+	Category    string  `json:"category"`
+	Subcategory string  `json:"subcategory"`
+	Amount      float64 `json:"amount"`
+	// Currency is the ISO 4217 code of the currency mentioned, empty when none is.
+	Currency string `json:"currency"`
+	// Account is the account the input names as paid from, empty when none is.
+	Account string `json:"account"`
+	// Merchant is the shop or business named in the input, empty when none is.
+	Merchant string `json:"merchant"`
+	// Date is when the input says the expense happened, as "2006-01-02" or
+	// "2006-01-02 15:04"; empty when it doesn't say.
+	Date          string `json:"date"`
+	IsTransaction bool   `json:"isTransaction"`
+	// Confidence is the model's certainty in Category/Subcategory, from 0 to 1.
+	Confidence float64 `json:"confidence"`
+	// Alternatives are up to three other likely pairs, most likely first.
+	Alternatives []Alternative `json:"alternatives"`
+	// Expenses lists every expense when the input mentions several, e.g.
+	// "coffee 65, taxi 230"; the fields above then describe the first one.
+	Expenses []Expense `json:"expenses"`
+}
+
+// Split returns one response per expense, or the response itself when it
+// describes a single expense.
+func (r *Response) Split() []Response {
+	if len(r.Expenses) < 2 {
+		return []Response{*r}
+	}
+	responses := make([]Response, 0, len(r.Expenses))
+	for _, expense := range r.Expenses {
+		// A currency or account mentioned once applies to every expense.
+		if expense.Currency == "" {
+			expense.Currency = r.Currency
+		}
+		if expense.Account == "" {
+			expense.Account = r.Account
+		}
+		if expense.Date == "" {
+			expense.Date = r.Date
+		}
+		responses = append(responses, Response{
+			Category:      expense.Category,
+			Subcategory:   expense.Subcategory,
+			Amount:        expense.Amount,
+			Currency:      expense.Currency,
+			Account:       expense.Account,
+			Merchant:      r.Merchant,
+			Date:          expense.Date,
+			IsTransaction: r.IsTransaction,
+			Confidence:    expense.Confidence,
+			Alternatives:  expense.Alternatives,
+		})
+	}
+	return responses
 }
 
 type AIService interface {
-	Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) *Response
+	// Request classifies userPrompt. It returns a non-nil error, one of the
+	// errors in this package when the cause is known, whenever the response is nil.
+	Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*Response, error)
 }
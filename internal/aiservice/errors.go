@@ -0,0 +1,20 @@
+package aiservice
+
+import "errors"
+
+// Errors returned by AIService.Request, wrapped with details. Match them with errors.Is.
+var (
+	ErrTimeout        = errors.New("AI request timed out")
+	ErrRateLimited    = errors.New("AI rate limit reached")
+	ErrRefusal        = errors.New("AI refused the request")
+	ErrInvalidJSON    = errors.New("AI returned invalid JSON")
+	ErrEmptyChoices   = errors.New("AI returned no choices")
+	ErrBudgetExceeded = errors.New("AI budget exceeded")
+	// ErrVisionUnsupported is returned for image requests to a service that can't read images.
+	ErrVisionUnsupported = errors.New("AI service cannot read images")
+)
+
+// IsRetryable reports whether err is transient, so the same request may succeed later.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrTimeout) || errors.Is(err, ErrRateLimited)
+}
@@ -0,0 +1,39 @@
+package aiservice
+
+import "context"
+
+type inputKey struct{}
+
+// WithInput returns a copy of ctx carrying text, the input a request
+// classifies before it is rendered into the prompt. Services that learn from
+// inputs, such as the offline model, read it with Input.
+func WithInput(ctx context.Context, text string) context.Context {
+	return context.WithValue(ctx, inputKey{}, text)
+}
+
+// Input returns the input set with WithInput, or "" when there is none.
+func Input(ctx *context.Context) string {
+	if ctx == nil || *ctx == nil {
+		return ""
+	}
+	text, _ := (*ctx).Value(inputKey{}).(string)
+	return text
+}
+
+type retryKey struct{}
+
+// WithRetry returns a copy of ctx marking that the caller retries the
+// request later after a transient error, so fallbacks leave those errors to
+// it. Without it, a request is the last attempt.
+func WithRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+// WillRetry reports whether ctx was marked with WithRetry.
+func WillRetry(ctx *context.Context) bool {
+	if ctx == nil || *ctx == nil {
+		return false
+	}
+	retry, _ := (*ctx).Value(retryKey{}).(bool)
+	return retry
+}
@@ -0,0 +1,15 @@
+package aiservice
+
+import "github.com/invopop/jsonschema"
+
+// GenerateSchema reflects T into a strict JSON schema that every provider
+// uses to constrain the model output.
+func GenerateSchema[T any]() interface{} {
+	reflector := jsonschema.Reflector{
+		AllowAdditionalProperties: false,
+		DoNotReference:            true,
+	}
+	var v T
+	schema := reflector.Reflect(v)
+	return schema
+}
@@ -0,0 +1,15 @@
+package aiservice
+
+// Usage is the token usage of a single AI call. Transcriptions billed by
+// the length of the audio report AudioSeconds instead.
+type Usage struct {
+	Model            string
+	PromptTokens     int64
+	CompletionTokens int64
+	AudioSeconds     float64
+}
+
+// UsageRecorder receives the usage of every AI call.
+type UsageRecorder interface {
+	Record(usage Usage)
+}
@@ -0,0 +1,28 @@
+package aiservice
+
+import "context"
+
+// Image is a picture sent along with the prompt, such as a receipt photo.
+type Image struct {
+	Data []byte
+	// MediaType is the MIME type of Data, e.g. "image/jpeg".
+	MediaType string
+}
+
+// VisionService is implemented by AI services whose models can read images.
+type VisionService interface {
+	// RequestImage classifies image, with userPrompt as the accompanying text.
+	// It returns errors like AIService.Request does.
+	RequestImage(name string, description string, systemPrompt string, userPrompt string, image Image, ctx *context.Context) (*Response, error)
+}
+
+// RequestImage sends image through service, or returns ErrVisionUnsupported
+// when service can't read images. Wrappers around an AIService use it to
+// pass image requests through.
+func RequestImage(service AIService, name string, description string, systemPrompt string, userPrompt string, image Image, ctx *context.Context) (*Response, error) {
+	vision, ok := service.(VisionService)
+	if !ok {
+		return nil, ErrVisionUnsupported
+	}
+	return vision.RequestImage(name, description, systemPrompt, userPrompt, image, ctx)
+}
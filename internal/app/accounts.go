@@ -0,0 +1,103 @@
+package app
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/profile"
+)
+
+// defaultCashAccounts are the cash wallets used unless MORPH_CASH_ACCOUNTS
+// configures others.
+var defaultCashAccounts = map[string]string{
+	"EUR": "CashEUR",
+	"UAH": "CashUAH",
+	"USD": "CashUSD",
+}
+
+// cashAccounts maps ISO currency codes to the MoneyWiz cash wallet for that
+// currency. It is read from MORPH_CASH_ACCOUNTS, e.g. "UAH=CashUAH,USD=CashUSD".
+var cashAccounts = loadAccountMap("MORPH_CASH_ACCOUNTS", defaultCashAccounts, strings.ToUpper)
+
+// accountAliases maps the names the user may write in a cash message, such as
+// "pumb", to a MoneyWiz account. It is read from MORPH_ACCOUNT_ALIASES, e.g.
+// "pumb=PUMBUAH,mono=MonobankUAH".
+var accountAliases = loadAccountMap("MORPH_ACCOUNT_ALIASES", nil, strings.ToLower)
+
+// memberAccounts maps the Telegram user IDs of group chat members to their
+// own cash wallet. It is read from MORPH_MEMBER_ACCOUNTS, e.g.
+// "111111=CashMax,222222=CashAnna".
+var memberAccounts = loadAccountMap("MORPH_MEMBER_ACCOUNTS", nil, strings.TrimSpace)
+
+func loadAccountMap(name string, defaults map[string]string, normalize func(string) string) map[string]string {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return defaults
+	}
+	accounts := map[string]string{}
+	for _, pair := range strings.Split(raw, ",") {
+		key, account, ok := strings.Cut(pair, "=")
+		key, account = strings.TrimSpace(key), strings.TrimSpace(account)
+		if !ok || key == "" || account == "" {
+			log.Printf("[Morph] Invalid %s entry %q", name, pair)
+			continue
+		}
+		accounts[normalize(key)] = account
+	}
+	return accounts
+}
+
+// accountAliasesJSON lists the alias names for the prompt, sorted so the
+// rendered prompt (and the cache key derived from it) is stable.
+func accountAliasesJSON(aliases map[string]string) string {
+	if len(aliases) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	data, _ := json.Marshal(names)
+	return string(data)
+}
+
+// cashAccountFor picks the account among accounts for a cash expense of the
+// Telegram user userID: an explicitly named account first, then the wallet
+// for the currency named, then, with no currency named, the user's own
+// wallet, then cashAccountName. A currency without a wallet gets no account,
+// rather than a wallet in another currency. Without configured wallets the
+// default ones are used.
+func cashAccountFor(accounts profile.Accounts, userID string, response *aiservice.Response) string {
+	if response.Account != "" {
+		if account, ok := accounts.Aliases[strings.ToLower(response.Account)]; ok {
+			return account
+		}
+		log.Printf("[Morph] Unknown account %q, using the currency wallet", response.Account)
+	}
+	if response.Currency != "" {
+		if account, ok := walletsOf(accounts)[strings.ToUpper(response.Currency)]; ok {
+			return account
+		}
+		log.Printf("[Morph] No cash wallet for currency %q", response.Currency)
+		return ""
+	}
+	// A member's wallet holds one currency, so it only takes expenses that
+	// name none.
+	if account, ok := accounts.Members[userID]; ok {
+		return account
+	}
+	return cashAccountName
+}
+
+// walletsOf returns the cash wallets per currency among accounts.
+func walletsOf(accounts profile.Accounts) map[string]string {
+	if len(accounts.Cash) == 0 {
+		return defaultCashAccounts
+	}
+	return accounts.Cash
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+)
+
+func setAccountAliases(t *testing.T, aliases map[string]string) {
+	t.Helper()
+	previous := accountAliases
+	accountAliases = aliases
+	t.Cleanup(func() { accountAliases = previous })
+}
+
+func TestCashAccountFor(t *testing.T) {
+	setAccountAliases(t, map[string]string{"pumb": "PUMBUAH"})
+	accounts := builtinProfile().Accounts
+	accounts.Members = map[string]string{"222": "CashAnna"}
+
+	tests := []struct {
+		name     string
+		userID   string
+		response aiservice.Response
+		want     string
+	}{
+		{"no currency", "1", aiservice.Response{}, cashAccountName},
+		{"currency wallet", "1", aiservice.Response{Currency: "uah"}, "CashUAH"},
+		{"unknown currency", "1", aiservice.Response{Currency: "PLN"}, ""},
+		{"named account", "1", aiservice.Response{Currency: "USD", Account: "PUMB"}, "PUMBUAH"},
+		{"unknown account", "1", aiservice.Response{Currency: "USD", Account: "privat"}, "CashUSD"},
+		{"member wallet", "222", aiservice.Response{}, "CashAnna"},
+		{"member naming a currency", "222", aiservice.Response{Currency: "USD"}, "CashUSD"},
+		{"member naming an account", "222", aiservice.Response{Account: "pumb"}, "PUMBUAH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cashAccountFor(accounts, tt.userID, &tt.response); got != tt.want {
+				t.Errorf("cashAccountFor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAccountMap(t *testing.T) {
+	t.Setenv("MORPH_CASH_ACCOUNTS", "uah=Wallet, usd = Dollars ,broken")
+
+	got := loadAccountMap("MORPH_CASH_ACCOUNTS", defaultCashAccounts, strings.ToUpper)
+	if len(got) != 2 || got["UAH"] != "Wallet" || got["USD"] != "Dollars" {
+		t.Errorf("Unexpected accounts: %v", got)
+	}
+}
+
+func TestCashHandler_CurrencyPicksCashWallet(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 1, ChatID: 2, Text: "200 грн таксі"}
+	fakes.ai.response = &aiservice.Response{Category: "Transport", Subcategory: "Taxi", Amount: 200, Currency: "UAH"}
+
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if len(fakes.deepLink.calls) != 1 || fakes.deepLink.calls[0].account != "CashUAH" {
+		t.Fatalf("deep link calls = %+v, want CashUAH", fakes.deepLink.calls)
+	}
+	if got := fakes.tasks.scheduledMessages[0].Text; !strings.Contains(got, "\nAccount: CashUAH") {
+		t.Fatalf("scheduled text = %q, want account line", got)
+	}
+}
+
+func TestCashHandler_CurrencyWithoutWalletIsFlagged(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 1, ChatID: 2, Text: "taxi 20 zł"}
+	fakes.ai.response = &aiservice.Response{Category: "Transport", Subcategory: "Taxi", Amount: 20, Currency: "PLN", Confidence: 0.95}
+
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if len(fakes.deepLink.calls) != 1 || fakes.deepLink.calls[0].account != "" || !fakes.deepLink.calls[0].draft {
+		t.Fatalf("deep link calls = %+v, want a draft without an account", fakes.deepLink.calls)
+	}
+	got := fakes.tasks.scheduledMessages[0].Text
+	if !strings.Contains(got, "No cash wallet for PLN") || strings.Contains(got, "Account:") {
+		t.Fatalf("scheduled text = %q, want the missing wallet flagged", got)
+	}
+}
+
+func TestCashHandler_GroupExpenseIsAttributedToTheSender(t *testing.T) {
+	fakes := installAppFakes(t)
+	installProfiles(t)
+	profiles[0].Accounts.Members = map[string]string{"3": "CashKids"}
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 65}
+
+	sendCash(fakes, botservice.BotMessage{MessageID: 80, ChatID: 111, Group: true, UserID: "3", UserName: "Ostap", Text: "coffee 65"})
+
+	if len(fakes.deepLink.calls) != 1 || fakes.deepLink.calls[0].account != "CashKids" {
+		t.Errorf("deep links = %+v, want the member's wallet", fakes.deepLink.calls)
+	}
+	if len(fakes.tasks.scheduledMessages) != 1 || !strings.HasPrefix(fakes.tasks.scheduledMessages[0].Text, "👤 Ostap\nCategory: Food") {
+		t.Errorf("scheduled = %+v, want the reply to name the sender", fakes.tasks.scheduledMessages)
+	}
+
+	fakes.tasks.scheduledMessages = nil
+	sendCash(fakes, botservice.BotMessage{MessageID: 81, ChatID: 111, UserID: "3", UserName: "Ostap", Text: "coffee 65"})
+	if len(fakes.tasks.scheduledMessages) != 1 || strings.Contains(fakes.tasks.scheduledMessages[0].Text, "Ostap") {
+		t.Errorf("scheduled = %+v, want no name in a private chat", fakes.tasks.scheduledMessages)
+	}
+
+	fakes.tasks.scheduledMessages = nil
+	sendCash(fakes, botservice.BotMessage{MessageID: 82, ChatID: 111, Group: true, UserID: "3", Text: "coffee 65"})
+	if len(fakes.tasks.scheduledMessages) != 1 || strings.Contains(fakes.tasks.scheduledMessages[0].Text, "👤") {
+		t.Errorf("scheduled = %+v, want no user ID in place of a name", fakes.tasks.scheduledMessages)
+	}
+}
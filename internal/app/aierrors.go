@@ -0,0 +1,59 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/locale"
+)
+
+// maxAIRetries is how many times Cloud Tasks may redeliver a task after a
+// transient AI error before the error is reported to the user.
+const maxAIRetries = 3
+
+// aiErrorMessage is the user-facing text for a failed classification, in loc.
+func aiErrorMessage(loc locale.Locale, err error) string {
+	// Errors from before the AI is reached come first, as they may wrap
+	// AI errors (a transcription timeout is not an AI timeout).
+	switch {
+	case errors.Is(err, errDownload):
+		return loc.Text("error.download")
+	case errors.Is(err, errNotImage):
+		return loc.Text("error.not_image")
+	case errors.Is(err, errSpeechUnsupported):
+		return loc.Text("error.speech_off")
+	case errors.Is(err, errTranscription):
+		return loc.Text("error.transcription")
+	case errors.Is(err, aiservice.ErrTimeout):
+		return loc.Text("error.timeout")
+	case errors.Is(err, aiservice.ErrRateLimited):
+		return loc.Text("error.rate_limited")
+	case errors.Is(err, aiservice.ErrRefusal):
+		return loc.Text("error.refusal")
+	case errors.Is(err, aiservice.ErrInvalidJSON):
+		return loc.Text("error.invalid_json")
+	case errors.Is(err, aiservice.ErrEmptyChoices):
+		return loc.Text("error.empty")
+	case errors.Is(err, aiservice.ErrBudgetExceeded):
+		return loc.Text("error.budget")
+	case errors.Is(err, aiservice.ErrVisionUnsupported):
+		return loc.Text("error.vision")
+	default:
+		return loc.Text("error.no_response")
+	}
+}
+
+// shouldRetryTask reports whether a Cloud Tasks request that failed with err
+// should be handed back to the queue for redelivery instead of reporting it.
+func shouldRetryTask(r *http.Request, err error) bool {
+	return aiservice.IsRetryable(err) && taskRetriesLeft(r)
+}
+
+// taskRetriesLeft reports whether Cloud Tasks will deliver r again if it
+// fails, so this is not the last attempt.
+func taskRetriesLeft(r *http.Request) bool {
+	retries, _ := strconv.Atoi(r.Header.Get("X-CloudTasks-TaskRetryCount"))
+	return retries < maxAIRetries
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+	"github.com/morph/internal/locale"
+)
+
+const retryableTransaction = `{"chatId":321,"mcc":5999,"category":"Miscellaneous and speciality retail outlets","description":"Rozetka","amount":120,"time":1746194127}`
+
+func TestCashHandler_AIErrorSchedulesSpecificMessage(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.message = &botservice.BotMessage{ChatID: 1, MessageID: 2, Text: "coffee 3"}
+	fakes.ai.err = fmt.Errorf("model gpt-4o: %w", aiservice.ErrRefusal)
+
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cash", strings.NewReader(`{}`)))
+
+	if len(fakes.tasks.scheduledMessages) != 1 {
+		t.Fatalf("scheduled messages = %d, want 1", len(fakes.tasks.scheduledMessages))
+	}
+	if got := fakes.tasks.scheduledMessages[0].Text; got != aiErrorMessage(locale.English, aiservice.ErrRefusal) {
+		t.Fatalf("scheduled text = %q, want refusal message", got)
+	}
+}
+
+func TestMonoHandler_RetryableAIErrorFailsTask(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.ai.err = aiservice.ErrRateLimited
+
+	req := httptest.NewRequest(http.MethodPost, "/monoHandler", strings.NewReader(retryableTransaction))
+	req.Header.Set("X-CloudTasks-TaskRetryCount", "1")
+	rr := httptest.NewRecorder()
+
+	MonoHandler(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+	if len(fakes.tasks.scheduledMessages) != 0 {
+		t.Fatalf("scheduled messages = %d, want none while retrying", len(fakes.tasks.scheduledMessages))
+	}
+}
+
+func TestMonoHandler_RetryableAIErrorReportedAfterLastRetry(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.ai.err = aiservice.ErrTimeout
+
+	req := httptest.NewRequest(http.MethodPost, "/monoHandler", strings.NewReader(retryableTransaction))
+	req.Header.Set("X-CloudTasks-TaskRetryCount", fmt.Sprint(maxAIRetries))
+	rr := httptest.NewRecorder()
+
+	MonoHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	if len(fakes.tasks.scheduledMessages) != 1 {
+		t.Fatalf("scheduled messages = %d, want 1", len(fakes.tasks.scheduledMessages))
+	}
+	if got := fakes.tasks.scheduledMessages[0].Text; got != aiErrorMessage(locale.English, aiservice.ErrTimeout) {
+		t.Fatalf("scheduled text = %q, want timeout message", got)
+	}
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/morph/internal/aicache"
+	"github.com/morph/internal/airecord"
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/budget"
+	"github.com/morph/internal/category"
+	"github.com/morph/internal/offlineai"
+	"github.com/morph/third_party/anthropic"
+	"github.com/morph/third_party/openai"
+)
+
+const defaultAITimeout = 30 * time.Second
+const defaultAICacheTTL = 7 * 24 * time.Hour
+
+// newAIService returns the configured AI provider behind the monthly budget
+// and the classification cache, so cached answers keep working once the
+// budget is used up. The offline model learns from the provider's answers
+// and answers whenever all of that fails for good. The offline and replay
+// providers are used as they are.
+func newAIService() aiservice.AIService {
+	provider := newAIProvider(aiBudget)
+	switch provider.(type) {
+	case *offlineai.Service, airecord.Replayer:
+		return provider
+	}
+	provider = withOfflineLearning(withRecording(provider))
+	return withOfflineFallback(withCache(budget.Guard{Service: provider, Budget: aiBudget}))
+}
+
+// newAIProvider picks the AI provider from MORPH_AI_PROVIDER:
+//   - "openai" (default): the OpenAI API
+//   - "openai-compatible": any OpenAI-compatible server at MORPH_AI_BASE_URL,
+//     e.g. a local Ollama (http://localhost:11434/v1) or llama.cpp server
+//   - "anthropic": the Anthropic Messages API
+//   - "offline": only the offline model trained on earlier classifications
+//   - "replay": the responses recorded in MORPH_AI_REPLAY_DIR, failing on any other prompt
+//
+// MORPH_AI_KEY and MORPH_AI_MODEL apply to whichever provider is selected.
+// Token usage of every call goes to recorder.
+func newAIProvider(recorder aiservice.UsageRecorder) aiservice.AIService {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("MORPH_AI_PROVIDER")))
+	apiKey := os.Getenv("MORPH_AI_KEY")
+	model := os.Getenv("MORPH_AI_MODEL")
+
+	switch provider {
+	case "", "openai":
+		return openai.New(openAIConfig(apiKey, "", model, recorder))
+	case "openai-compatible":
+		baseURL := strings.TrimSpace(os.Getenv("MORPH_AI_BASE_URL"))
+		if baseURL == "" {
+			// The OpenAI client would default to api.openai.com, sending
+			// inputs meant for a local model to OpenAI.
+			log.Printf("[Morph] MORPH_AI_BASE_URL is not set for the openai-compatible provider, refusing AI requests")
+			return unconfiguredAI{err: errMissingBaseURL}
+		}
+		return openai.New(openAIConfig(apiKey, baseURL, model, recorder))
+	case "anthropic":
+		return anthropic.Anthropic{APIKey: apiKey, Model: model, Recorder: recorder}
+	case "offline":
+		return offlineAI
+	case "replay":
+		return airecord.Replayer{Dir: os.Getenv("MORPH_AI_REPLAY_DIR")}
+	default:
+		log.Printf("[Morph] Unknown AI provider %q, using OpenAI", provider)
+		return openai.New(openAIConfig(apiKey, "", model, recorder))
+	}
+}
+
+var errMissingBaseURL = errors.New("MORPH_AI_BASE_URL is not set for the openai-compatible provider")
+
+// unconfiguredAI refuses every request of a provider that is not configured
+// well enough to be called.
+type unconfiguredAI struct {
+	err error
+}
+
+func (ai unconfiguredAI) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	return nil, ai.err
+}
+
+// openAIConfig reads the OpenAI tuning knobs:
+//   - MORPH_AI_FALLBACK_MODELS: comma-separated models tried in order after MORPH_AI_MODEL fails
+//   - MORPH_AI_TEMPERATURE: sampling temperature, API default when unset
+//   - MORPH_AI_TIMEOUT: per-model timeout as a Go duration (e.g. "20s"), 30s by default
+//   - MORPH_AI_VISION_MODEL: model that reads receipt photos, the models above when unset
+func openAIConfig(apiKey string, baseURL string, model string, recorder aiservice.UsageRecorder) openai.Config {
+	config := openai.Config{
+		APIKey:      apiKey,
+		BaseURL:     baseURL,
+		Model:       model,
+		VisionModel: os.Getenv("MORPH_AI_VISION_MODEL"),
+		Timeout:     defaultAITimeout,
+		Recorder:    recorder,
+	}
+
+	for _, fallback := range strings.Split(os.Getenv("MORPH_AI_FALLBACK_MODELS"), ",") {
+		if fallback = strings.TrimSpace(fallback); fallback != "" {
+			config.FallbackModels = append(config.FallbackModels, fallback)
+		}
+	}
+
+	if raw := os.Getenv("MORPH_AI_TEMPERATURE"); raw != "" {
+		if temperature, err := strconv.ParseFloat(raw, 64); err == nil {
+			config.Temperature = &temperature
+		} else {
+			log.Printf("[Morph] Invalid MORPH_AI_TEMPERATURE %q: %v", raw, err)
+		}
+	}
+
+	if raw := os.Getenv("MORPH_AI_TIMEOUT"); raw != "" {
+		if timeout, err := time.ParseDuration(raw); err == nil {
+			config.Timeout = timeout
+		} else {
+			log.Printf("[Morph] Invalid MORPH_AI_TIMEOUT %q: %v", raw, err)
+		}
+	}
+
+	return config
+}
+
+// withRecording records every response of service to a fixture in
+// MORPH_AI_RECORD_DIR, when set, for later replay.
+func withRecording(service aiservice.AIService) aiservice.AIService {
+	dir := os.Getenv("MORPH_AI_RECORD_DIR")
+	if dir == "" {
+		return service
+	}
+	log.Printf("[Morph] Recording AI responses to %s", dir)
+	return &airecord.Recorder{Service: service, Dir: dir}
+}
+
+// withCache wraps service in the classification cache selected by MORPH_AI_CACHE:
+// "memory" (default) for a per-instance cache, "storage" to persist entries
+// through the configured storage, or "off". MORPH_AI_CACHE_TTL sets how long
+// entries live as a Go duration, a week by default.
+func withCache(service aiservice.AIService) aiservice.AIService {
+	ttl := defaultAICacheTTL
+	if raw := os.Getenv("MORPH_AI_CACHE_TTL"); raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil {
+			ttl = parsed
+		} else {
+			log.Printf("[Morph] Invalid MORPH_AI_CACHE_TTL %q: %v", raw, err)
+		}
+	}
+
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv("MORPH_AI_CACHE"))); mode {
+	case "", "memory":
+		return aicache.New(service, aicache.NewMemoryBackend(), ttl, category.Version)
+	case "storage":
+		return aicache.New(service, aicache.StorageBackend{Storage: store}, ttl, category.Version)
+	case "off":
+		return service
+	default:
+		log.Printf("[Morph] Unknown AI cache %q, using memory", mode)
+		return aicache.New(service, aicache.NewMemoryBackend(), ttl, category.Version)
+	}
+}
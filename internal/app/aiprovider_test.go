@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/morph/internal/aicache"
+	"github.com/morph/internal/airecord"
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/offlineai"
+	"github.com/morph/third_party/anthropic"
+	"github.com/morph/third_party/openai"
+)
+
+func TestNewAIProvider_SelectsProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		baseURL  string
+		want     any
+	}{
+		{
+			name:     "default is OpenAI",
+			provider: "",
+			want:     openai.Config{APIKey: "key", Model: "model", Timeout: defaultAITimeout},
+		},
+		{
+			name:     "OpenAI-compatible server",
+			provider: "openai-compatible",
+			baseURL:  "http://localhost:11434/v1",
+			want:     openai.Config{APIKey: "key", BaseURL: "http://localhost:11434/v1", Model: "model", Timeout: defaultAITimeout},
+		},
+		{
+			name:     "Anthropic",
+			provider: "Anthropic",
+			want:     anthropic.Anthropic{APIKey: "key", Model: "model"},
+		},
+		{
+			name:     "unknown provider falls back to OpenAI",
+			provider: "unknown",
+			want:     openai.Config{APIKey: "key", Model: "model", Timeout: defaultAITimeout},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MORPH_AI_PROVIDER", tt.provider)
+			t.Setenv("MORPH_AI_BASE_URL", tt.baseURL)
+			t.Setenv("MORPH_AI_KEY", "key")
+			t.Setenv("MORPH_AI_MODEL", "model")
+
+			var got any = newAIProvider(nil)
+			if service, ok := got.(*openai.OpenAI); ok {
+				got = service.Config()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newAIProvider() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAIProvider_OpenAICompatibleWithoutBaseURLRefuses(t *testing.T) {
+	t.Setenv("MORPH_AI_PROVIDER", "openai-compatible")
+	t.Setenv("MORPH_AI_BASE_URL", " ")
+
+	service := newAIProvider(nil)
+	if _, ok := service.(*openai.OpenAI); ok {
+		t.Fatal("Expected no OpenAI client without a base URL")
+	}
+	ctx := context.Background()
+	if _, err := service.Request("Morph", "desc", "system", "user", &ctx); !errors.Is(err, errMissingBaseURL) {
+		t.Errorf("Expected requests to be refused, got %v", err)
+	}
+}
+
+func TestOpenAIConfig_ReadsTuningFromEnv(t *testing.T) {
+	t.Setenv("MORPH_AI_FALLBACK_MODELS", "gpt-4o-mini, ,gpt-4.1-mini")
+	t.Setenv("MORPH_AI_TEMPERATURE", "0.1")
+	t.Setenv("MORPH_AI_TIMEOUT", "15s")
+
+	config := openAIConfig("key", "", "gpt-4o", nil)
+
+	if !reflect.DeepEqual(config.FallbackModels, []string{"gpt-4o-mini", "gpt-4.1-mini"}) {
+		t.Errorf("fallback models = %v, want [gpt-4o-mini gpt-4.1-mini]", config.FallbackModels)
+	}
+	if config.Temperature == nil || *config.Temperature != 0.1 {
+		t.Errorf("temperature = %v, want 0.1", config.Temperature)
+	}
+	if config.Timeout != 15*time.Second {
+		t.Errorf("timeout = %s, want 15s", config.Timeout)
+	}
+}
+
+func TestWithCache_SelectsBackend(t *testing.T) {
+	service := anthropic.Anthropic{}
+
+	t.Setenv("MORPH_AI_CACHE", "")
+	if _, ok := withCache(service).(*aicache.Cache); !ok {
+		t.Error("Expected the cache to be on by default")
+	}
+
+	t.Setenv("MORPH_AI_CACHE", "storage")
+	if _, ok := withCache(service).(*aicache.Cache); !ok {
+		t.Error("Expected a storage-backed cache")
+	}
+
+	t.Setenv("MORPH_AI_CACHE", "off")
+	if got := withCache(service); got != service {
+		t.Errorf("Expected the provider unwrapped, got %#v", got)
+	}
+}
+
+func TestNewAIService_Offline(t *testing.T) {
+	t.Setenv("MORPH_AI_PROVIDER", "offline")
+	if got := newAIService(); got != aiservice.AIService(offlineAI) {
+		t.Errorf("Expected the offline model alone, got %#v", got)
+	}
+}
+
+func TestNewAIService_ReplayIsNotWrapped(t *testing.T) {
+	t.Setenv("MORPH_AI_PROVIDER", "replay")
+	t.Setenv("MORPH_AI_REPLAY_DIR", "testdata/ai")
+
+	if got := newAIService(); got != aiservice.AIService(airecord.Replayer{Dir: "testdata/ai"}) {
+		t.Errorf("Expected the replayer alone, got %#v", got)
+	}
+}
+
+func TestWithOfflineFallback(t *testing.T) {
+	service := anthropic.Anthropic{}
+
+	t.Setenv("MORPH_AI_OFFLINE_FALLBACK", "")
+	if _, ok := withOfflineFallback(service).(offlineai.Fallback); !ok {
+		t.Error("Expected the offline fallback to be on by default")
+	}
+
+	if _, ok := withOfflineLearning(service).(offlineai.Teacher); !ok {
+		t.Error("Expected the offline model to learn by default")
+	}
+
+	t.Setenv("MORPH_AI_OFFLINE_FALLBACK", "off")
+	if got := withOfflineFallback(service); got != service {
+		t.Errorf("Expected the service unwrapped, got %#v", got)
+	}
+	if got := withOfflineLearning(service); got != service {
+		t.Errorf("Expected the service unwrapped, got %#v", got)
+	}
+}
+
+func TestNewAIProvider_Replay(t *testing.T) {
+	t.Setenv("MORPH_AI_PROVIDER", "replay")
+	t.Setenv("MORPH_AI_REPLAY_DIR", "testdata/ai")
+
+	if got := newAIProvider(nil); got != aiservice.AIService(airecord.Replayer{Dir: "testdata/ai"}) {
+		t.Errorf("Expected a replayer of testdata/ai, got %#v", got)
+	}
+}
+
+func TestWithRecording(t *testing.T) {
+	service := anthropic.Anthropic{}
+
+	t.Setenv("MORPH_AI_RECORD_DIR", "")
+	if got := withRecording(service); got != service {
+		t.Errorf("Expected the service unwrapped, got %#v", got)
+	}
+
+	t.Setenv("MORPH_AI_RECORD_DIR", t.TempDir())
+	if _, ok := withRecording(service).(*airecord.Recorder); !ok {
+		t.Error("Expected a recorder when MORPH_AI_RECORD_DIR is set")
+	}
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/morph/internal/budget"
+)
+
+var aiBudget = newAIBudget()
+
+// newAIBudget tracks AI usage against MORPH_AI_MONTHLY_BUDGET (USD). Without
+// a budget, usage is still recorded but never limited.
+func newAIBudget() *budget.Budget {
+	limit := 0.0
+	if raw := os.Getenv("MORPH_AI_MONTHLY_BUDGET"); raw != "" {
+		if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+			limit = parsed
+		} else {
+			log.Printf("[Morph] Invalid MORPH_AI_MONTHLY_BUDGET %q: %v", raw, err)
+		}
+	}
+	return budget.New(store, limit, warnBudgetExceeded)
+}
+
+// warnBudgetExceeded tells us the AI is off until the next month.
+func warnBudgetExceeded(month budget.Month, limit float64) {
+	owner := defaultProfile()
+	chatID, err := profileChat(owner)
+	if err != nil {
+		log.Printf("[Morph] Error getting chat ID: %v", err)
+		return
+	}
+
+	text := chatLocale(chatID, owner).Text("budget.exceeded", limit, month.Month, month.Cost, month.Calls)
+	bot.SendMessage(chatID, text, "", nil)
+	log.Printf("[Morph] Sent AI budget warning for %s", month.Month)
+}
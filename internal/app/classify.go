@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/profile"
+	"github.com/morph/internal/prompt"
+)
+
+// renderPrompt renders the prompt template for source with the owner's
+// taxonomy and accounts. A non-zero sentAt lets the AI resolve relative
+// dates in text.
+func renderPrompt(owner *profile.Profile, source prompt.Source, text string, sentAt time.Time) (prompt.Prompt, error) {
+	taxonomy := owner.Taxonomy()
+	input := prompt.Input{
+		Source:   source,
+		Taxonomy: taxonomy.JSON(),
+		Hints:    taxonomy.HintsJSON(),
+		Accounts: accountAliasesJSON(owner.Accounts.Aliases),
+		Text:     text,
+	}
+	if !sentAt.IsZero() {
+		input.Today = formatToday(sentAt)
+	}
+	rendered, err := prompt.Render(input)
+	if err != nil {
+		log.Printf("[Morph] Could not render prompt: %v", err)
+	}
+	return rendered, err
+}
+
+// logClassification logs the result together with the template version so
+// classifications from different prompt versions can be compared.
+func logClassification(rendered prompt.Prompt, response *aiservice.Response, err error) {
+	if err != nil {
+		log.Printf("[Morph] No classification from prompt %s: %v", rendered.ID(), err)
+		return
+	}
+	log.Printf("[Morph] Classified with prompt %s: %s/%s %.2f (confidence %.2f)", rendered.ID(), response.Category, response.Subcategory, response.Amount, response.Confidence)
+}
+
+// classify renders the prompt template for source and asks the AI to
+// classify text for owner.
+func classify(ctx *context.Context, owner *profile.Profile, source prompt.Source, text string, sentAt time.Time) (*aiservice.Response, error) {
+	rendered, err := renderPrompt(owner, source, text, sentAt)
+	if err != nil {
+		return nil, err
+	}
+
+	inputCtx := aiservice.WithInput(*ctx, text)
+	response, err := aiService.Request("Morph", rendered.Description, rendered.System, rendered.User, &inputCtx)
+	logClassification(rendered, response, err)
+	return response, err
+}
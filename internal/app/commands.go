@@ -0,0 +1,198 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/morph/internal/botservice"
+	"github.com/morph/internal/locale"
+	"github.com/morph/internal/prompt"
+	"github.com/morph/internal/taskservice"
+)
+
+// command is a bot command such as /help. handle returns the reply in loc.
+type command struct {
+	name   string
+	handle func(ctx *context.Context, message *botservice.BotMessage, loc locale.Locale) string
+}
+
+// commands are listed by /help in this order. They are registered in init
+// because /help itself reads the list.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"help", helpCommand},
+		{"categories", categoriesCommand},
+		{"accounts", accountsCommand},
+		{"undo", undoCommand},
+		{"status", statusCommand},
+		{"language", languageCommand},
+	}
+}
+
+// handleCommand replies to a bot command instead of classifying it.
+func handleCommand(ctx *context.Context, message *botservice.BotMessage) {
+	log.Printf("[Morph] Command /%s", message.Command)
+
+	loc := chatLocale(message.ChatID, profileForMessage(message))
+	text := loc.Text("command.unknown", message.Command)
+	for _, c := range commands {
+		if c.name == message.Command {
+			text = c.handle(ctx, message, loc)
+			break
+		}
+	}
+
+	scheduledMessage := taskservice.ScheduledMessage{
+		ChatID:           message.ChatID,
+		Text:             text,
+		ReplyToMessageID: &message.MessageID,
+	}
+	taskService.ScheduleMessage(ctx, scheduledMessage, time.Now())
+}
+
+func helpCommand(ctx *context.Context, message *botservice.BotMessage, loc locale.Locale) string {
+	lines := []string{loc.Text("help.intro"), ""}
+	for _, c := range commands {
+		lines = append(lines, fmt.Sprintf("/%s — %s", c.name, loc.Text("command."+c.name)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func categoriesCommand(ctx *context.Context, message *botservice.BotMessage, loc locale.Locale) string {
+	categories := profileForMessage(message).Taxonomy()
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{loc.Text("categories.title")}
+	for _, name := range names {
+		if len(categories[name]) == 0 {
+			lines = append(lines, loc.Category(name))
+			continue
+		}
+		subcategories := make([]string, 0, len(categories[name]))
+		for _, subcategory := range categories[name] {
+			subcategories = append(subcategories, loc.Subcategory(subcategory))
+		}
+		lines = append(lines, loc.Category(name)+": "+strings.Join(subcategories, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func accountsCommand(ctx *context.Context, message *botservice.BotMessage, loc locale.Locale) string {
+	accounts := profileForMessage(message).Accounts
+	lines := []string{loc.Text("accounts.wallets")}
+	lines = append(lines, sortedPairs(walletsOf(accounts))...)
+	lines = append(lines, loc.Text("accounts.default", cashAccountName), "", loc.Text("accounts.names"))
+	if len(accounts.Aliases) == 0 {
+		lines = append(lines, loc.Text("accounts.no_names"))
+	} else {
+		lines = append(lines, sortedPairs(accounts.Aliases)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sortedPairs lists a mapping as "key → value" lines, sorted by key.
+func sortedPairs(mapping map[string]string) []string {
+	lines := make([]string, 0, len(mapping))
+	for key, value := range mapping {
+		lines = append(lines, key+" → "+value)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func undoCommand(ctx *context.Context, message *botservice.BotMessage, loc locale.Locale) string {
+	entry, ok := loadLastEntry(message.ChatID)
+	if !ok || entry.Undone {
+		return loc.Text("undo.nothing")
+	}
+	// Bank transactions answer no message, so their reply isn't known.
+	if entry.MessageID == 0 {
+		return loc.Text("undo.bank", entry.Summary)
+	}
+	entry.Undone = true
+	saveLastEntry(message.ChatID, entry)
+
+	// Deleting the reply takes its link out of the chat.
+	if replyID, ok := findReply(message.ChatID, entry.MessageID); ok {
+		scheduledDeletion := taskservice.ScheduledDeletion{
+			ChatID:    message.ChatID,
+			MessageID: replyID,
+		}
+		taskService.ScheduleDeletion(ctx, scheduledDeletion, time.Now())
+		return loc.Text("undo.deleted", entry.Summary)
+	}
+	return loc.Text("undo.retracted", entry.Summary)
+}
+
+func statusCommand(ctx *context.Context, message *botservice.BotMessage, loc locale.Locale) string {
+	provider := os.Getenv("MORPH_AI_PROVIDER")
+	if provider == "" {
+		provider = "openai"
+	}
+	if model := os.Getenv("MORPH_AI_MODEL"); model != "" {
+		provider += " (" + model + ")"
+	}
+	lines := []string{loc.Text("status.title"), loc.Text("status.ai", provider)}
+
+	month := aiBudget.Current()
+	if limit := aiBudget.Limit(); limit > 0 {
+		budgetLine := loc.Text("status.budget", month.Cost, limit, month.Month, month.Calls)
+		if aiBudget.Exceeded() {
+			budgetLine += loc.Text("status.used_up")
+		}
+		lines = append(lines, budgetLine)
+	} else {
+		lines = append(lines, loc.Text("status.usage", month.Cost, month.Month, month.Calls))
+	}
+
+	examples, classes := offlineAI.Size()
+	lines = append(lines, loc.Text("status.offline", examples, classes))
+
+	voice := loc.Text("status.voice_on")
+	if speechService == nil {
+		voice = loc.Text("status.voice_off")
+	}
+	lines = append(lines, voice, loc.Text("status.taxonomy", profileForMessage(message).Taxonomy().Version()))
+
+	var templates []string
+	for _, source := range []prompt.Source{prompt.SourceCash, prompt.SourceMono, prompt.SourceNotification, prompt.SourceReceipt} {
+		if t, ok := prompt.Get(source); ok {
+			templates = append(templates, fmt.Sprintf("%s@%d", t.Name, t.Version))
+		}
+	}
+	lines = append(lines, loc.Text("status.prompts", strings.Join(templates, ", ")))
+	return strings.Join(lines, "\n")
+}
+
+// languageCommand shows the language of the replies, or switches the chat to
+// the one given, e.g. /language uk.
+func languageCommand(ctx *context.Context, message *botservice.BotMessage, loc locale.Locale) string {
+	supported := locale.Supported()
+	codes := make([]string, 0, len(supported))
+	for _, l := range supported {
+		codes = append(codes, fmt.Sprintf("%s (%s)", l, l.Name()))
+	}
+	available := strings.Join(codes, ", ")
+
+	code := strings.TrimSpace(message.Args)
+	if code == "" {
+		return loc.Text("language.current", loc.Name(), available)
+	}
+	chosen, ok := locale.Parse(code)
+	if !ok {
+		return loc.Text("language.unknown", code, available)
+	}
+	setChatLocale(message.ChatID, chosen)
+	return chosen.Text("language.set", chosen.Name())
+}
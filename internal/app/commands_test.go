@@ -0,0 +1,145 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+	"github.com/morph/internal/taskservice"
+)
+
+func sendCommand(t *testing.T, fakes appFakes, name string) string {
+	t.Helper()
+	fakes.bot.message = &botservice.BotMessage{MessageID: 5, ChatID: 777, Text: "/" + name, Command: name}
+	fakes.tasks.scheduledMessages = nil
+
+	rr := httptest.NewRecorder()
+	CashHandler(rr, httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	if len(fakes.tasks.scheduledMessages) != 1 {
+		t.Fatalf("scheduled messages = %d, want 1", len(fakes.tasks.scheduledMessages))
+	}
+	reply := fakes.tasks.scheduledMessages[0]
+	if reply.ChatID != 777 || reply.ReplyToMessageID == nil || *reply.ReplyToMessageID != 5 {
+		t.Fatalf("reply = %+v, want a reply to the command", reply)
+	}
+	return reply.Text
+}
+
+func TestCommands_AreNotClassified(t *testing.T) {
+	fakes := installAppFakes(t)
+
+	for _, c := range commands {
+		sendCommand(t, fakes, c.name)
+	}
+	if text := sendCommand(t, fakes, "start"); !strings.Contains(text, "Unknown command /start") {
+		t.Errorf("unknown command reply = %q", text)
+	}
+	if fakes.ai.callCount != 0 {
+		t.Errorf("AI calls = %d, want 0", fakes.ai.callCount)
+	}
+}
+
+func TestHelpCommand_ListsCommands(t *testing.T) {
+	fakes := installAppFakes(t)
+
+	text := sendCommand(t, fakes, "help")
+	for _, c := range commands {
+		if !strings.Contains(text, "/"+c.name+" — ") {
+			t.Errorf("help is missing /%s:\n%s", c.name, text)
+		}
+	}
+}
+
+func TestCategoriesCommand_RendersTaxonomy(t *testing.T) {
+	fakes := installAppFakes(t)
+
+	text := sendCommand(t, fakes, "categories")
+	for _, want := range []string{"Food: Shop, Alcohol, Outdoors, Other", "\nWaste", "Transport: Subway, Taxi"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("categories are missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestAccountsCommand_ListsWalletsAndAliases(t *testing.T) {
+	fakes := installAppFakes(t)
+	oldAliases := accountAliases
+	accountAliases = map[string]string{"pumb": "PUMBUAH"}
+	t.Cleanup(func() { accountAliases = oldAliases })
+
+	text := sendCommand(t, fakes, "accounts")
+	for _, want := range []string{"UAH → CashUAH", "Without a currency: CashEUR", "pumb → PUMBUAH"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("accounts are missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestUndoCommand_RetractsTheLastEntryOnce(t *testing.T) {
+	fakes := installAppFakes(t)
+
+	if text := sendCommand(t, fakes, "undo"); text != "Nothing to undo" {
+		t.Fatalf("undo without entries = %q", text)
+	}
+
+	fakes.bot.message = &botservice.BotMessage{MessageID: 4, ChatID: 777, Text: "coffee 65"}
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 65}
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if text := sendCommand(t, fakes, "undo"); !strings.Contains(text, "Retracted: Food/Outdoors 65.00") {
+		t.Errorf("undo = %q, want the last entry retracted", text)
+	}
+	if len(fakes.tasks.scheduledDeletions) != 0 {
+		t.Errorf("deletions = %+v, want none for a reply that was never sent", fakes.tasks.scheduledDeletions)
+	}
+	if text := sendCommand(t, fakes, "undo"); text != "Nothing to undo" {
+		t.Errorf("second undo = %q, want nothing left", text)
+	}
+}
+
+func TestStatusCommand(t *testing.T) {
+	fakes := installAppFakes(t)
+	t.Setenv("MORPH_AI_PROVIDER", "anthropic")
+	t.Setenv("MORPH_AI_MODEL", "claude-test")
+
+	text := sendCommand(t, fakes, "status")
+	for _, want := range []string{"AI: anthropic (claude-test)", "Offline model: 0 examples", "Prompts: cash@"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("status is missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestUndoCommand_DeletesTheSentReply(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 65}
+	sendCash(fakes, botservice.BotMessage{MessageID: 4, ChatID: 777, Text: "coffee 65"})
+	deliver(t, fakes)
+
+	if text := sendCommand(t, fakes, "undo"); !strings.Contains(text, "link was deleted") {
+		t.Errorf("undo = %q, want the link deleted", text)
+	}
+	want := taskservice.ScheduledDeletion{ChatID: 777, MessageID: 1001}
+	if len(fakes.tasks.scheduledDeletions) != 1 || fakes.tasks.scheduledDeletions[0] != want {
+		t.Errorf("deletions = %+v, want %+v", fakes.tasks.scheduledDeletions, want)
+	}
+}
+
+func TestUndoCommand_BankEntryCannotBeRetracted(t *testing.T) {
+	fakes := installAppFakes(t)
+	rememberEntry(777, 0, "Food/Shop 250.50")
+
+	if text := sendCommand(t, fakes, "undo"); !strings.Contains(text, "Food/Shop 250.50 came from the bank") {
+		t.Errorf("undo = %q, want a note that bank entries stay", text)
+	}
+	if len(fakes.tasks.scheduledDeletions) != 0 {
+		t.Errorf("deletions = %+v, want none", fakes.tasks.scheduledDeletions)
+	}
+}
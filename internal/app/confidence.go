@@ -0,0 +1,84 @@
+package app
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/category"
+	"github.com/morph/internal/profile"
+	"github.com/morph/internal/render"
+)
+
+const maxAlternatives = 3
+
+// confidenceThreshold is read from MORPH_AI_CONFIDENCE_THRESHOLD (0 to 1).
+// Below it the user picks among the candidates instead of getting a link
+// that saves right away. Zero, the default, disables the check.
+var confidenceThreshold = loadConfidenceThreshold()
+
+func loadConfidenceThreshold() float64 {
+	raw := os.Getenv("MORPH_AI_CONFIDENCE_THRESHOLD")
+	if raw == "" {
+		return 0
+	}
+	threshold, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		log.Printf("[Morph] Invalid MORPH_AI_CONFIDENCE_THRESHOLD %q: %v", raw, err)
+		return 0
+	}
+	return threshold
+}
+
+// candidates returns the classification followed by its distinct
+// alternatives that are valid in taxonomy.
+func candidates(taxonomy category.Taxonomy, response *aiservice.Response) []aiservice.Alternative {
+	result := []aiservice.Alternative{{Category: response.Category, Subcategory: response.Subcategory}}
+	for _, alternative := range response.Alternatives {
+		if len(result) > maxAlternatives {
+			break
+		}
+		if !taxonomy.IsValid(alternative.Category, alternative.Subcategory) {
+			continue
+		}
+		duplicate := false
+		for _, existing := range result {
+			if existing == alternative {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			result = append(result, alternative)
+		}
+	}
+	return result
+}
+
+// classificationLinks returns the deep link for the classification, a draft
+// when there is no account, for the user to pick one. When the AI is less
+// confident than the threshold and offered alternatives in the owner's
+// taxonomy, it returns one draft link per candidate for the user to pick
+// from instead.
+func classificationLinks(owner *profile.Profile, response *aiservice.Response, account string, amount float64, date time.Time) []render.Link {
+	options := candidates(owner.Taxonomy(), response)
+	if response.Confidence >= confidenceThreshold || len(options) < 2 {
+		if account == "" {
+			return []render.Link{shortLink(deepLinkGenerator.CreateDraft(response.Category, response.Subcategory, account, amount, date))}
+		}
+		deepLink := deepLinkGenerator.Create(response.Category, response.Subcategory, account, amount, date)
+		return []render.Link{shortLink(deepLink)}
+	}
+
+	log.Printf("[Morph] Low confidence %.2f, offering %d candidates", response.Confidence, len(options))
+	links := make([]render.Link, 0, len(options))
+	for _, option := range options {
+		deepLink := deepLinkGenerator.CreateDraft(option.Category, option.Subcategory, account, amount, date)
+		link := shortLink(deepLink)
+		link.Category, link.Subcategory = option.Category, option.Subcategory
+		links = append(links, link)
+	}
+	return links
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+)
+
+func setConfidenceThreshold(t *testing.T, threshold float64) {
+	t.Helper()
+	old := confidenceThreshold
+	confidenceThreshold = threshold
+	t.Cleanup(func() {
+		confidenceThreshold = old
+	})
+}
+
+func TestCashHandler_LowConfidenceOffersDraftLinksPerCandidate(t *testing.T) {
+	fakes := installAppFakes(t)
+	setConfidenceThreshold(t, 0.7)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 1, ChatID: 2, Text: "lessons 400"}
+	fakes.ai.response = &aiservice.Response{
+		Category:    "Children",
+		Subcategory: "Vocal",
+		Amount:      400,
+		Confidence:  0.4,
+		Alternatives: []aiservice.Alternative{
+			{Category: "Education", Subcategory: "Courses"},
+			{Category: "Children", Subcategory: "Vocal"},
+			{Category: "Invented", Subcategory: "Thing"},
+			{Category: "Activities", Subcategory: "Sport"},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`))
+	rr := httptest.NewRecorder()
+
+	CashHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	if len(fakes.deepLink.calls) != 3 {
+		t.Fatalf("deep link calls = %d, want 3", len(fakes.deepLink.calls))
+	}
+	for _, call := range fakes.deepLink.calls {
+		if !call.draft {
+			t.Fatalf("deep link call = %+v, want draft link", call)
+		}
+	}
+	got := fakes.tasks.scheduledMessages[0].Text
+	wantText := "Category: Children\nSubcategory: Vocal\nAmount: 400.00\n🤔 Not sure (40%), pick one:" +
+		"\n1. Children / Vocal\nhttps://short.example/link" +
+		"\n2. Education / Courses\nhttps://short.example/link" +
+		"\n3. Activities / Sport\nhttps://short.example/link"
+	if got != wantText {
+		t.Fatalf("scheduled text = %q, want %q", got, wantText)
+	}
+}
+
+func TestCashHandler_ConfidentClassificationSavesDirectly(t *testing.T) {
+	fakes := installAppFakes(t)
+	setConfidenceThreshold(t, 0.7)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 1, ChatID: 2, Text: "taxi 10"}
+	fakes.ai.response = &aiservice.Response{
+		Category:     "Transport",
+		Subcategory:  "Taxi",
+		Amount:       10,
+		Confidence:   0.95,
+		Alternatives: []aiservice.Alternative{{Category: "Transport", Subcategory: "Bus"}},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`))
+	rr := httptest.NewRecorder()
+
+	CashHandler(rr, req)
+
+	if len(fakes.deepLink.calls) != 1 || fakes.deepLink.calls[0].draft {
+		t.Fatalf("deep link calls = %+v, want one saving link", fakes.deepLink.calls)
+	}
+}
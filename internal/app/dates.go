@@ -0,0 +1,62 @@
+package app
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// oldestDate bounds how far back a date in a message may resolve; anything
+// older is more likely a misread amount (e.g. "15.09" meant as money).
+const oldestDate = 365 * 24 * time.Hour
+
+// timezone is where dates in messages are resolved, read from MORPH_TIMEZONE
+// as an IANA name (Europe/Kyiv by default).
+var timezone = loadTimezone()
+
+func loadTimezone() *time.Location {
+	name := os.Getenv("MORPH_TIMEZONE")
+	if name == "" {
+		name = "Europe/Kyiv"
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("[Morph] Invalid MORPH_TIMEZONE %q: %v", name, err)
+		// Fallback to a fixed winter offset (UTC+02:00) if the location cannot be loaded.
+		return time.FixedZone("EET", 2*60*60)
+	}
+	return loc
+}
+
+// formatToday is the day a message was sent, as given to the prompt.
+func formatToday(sentAt time.Time) string {
+	return sentAt.In(timezone).Format("Monday, 2006-01-02")
+}
+
+// resolveDate turns the date the AI extracted from a message sent at sentAt
+// into the time of the expense. A day without a time keeps the time of day
+// the message was sent. It reports false, and returns sentAt, when there is
+// no date or it is unusable.
+func resolveDate(date string, sentAt time.Time) (time.Time, bool) {
+	if date == "" {
+		return sentAt, false
+	}
+
+	local := sentAt.In(timezone)
+	resolved, err := time.ParseInLocation("2006-01-02 15:04", date, timezone)
+	if err != nil {
+		day, dayErr := time.ParseInLocation("2006-01-02", date, timezone)
+		if dayErr != nil {
+			log.Printf("[Morph] Could not parse date %q, using the message date", date)
+			return sentAt, false
+		}
+		resolved = time.Date(day.Year(), day.Month(), day.Day(), local.Hour(), local.Minute(), local.Second(), 0, timezone)
+	}
+
+	sentDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, timezone)
+	if !resolved.Before(sentDay.AddDate(0, 0, 1)) || resolved.Before(sentAt.Add(-oldestDate)) {
+		log.Printf("[Morph] Date %q is out of range for a message sent %s, using the message date", date, local.Format(time.RFC3339))
+		return sentAt, false
+	}
+	return resolved, true
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+)
+
+func TestResolveDate(t *testing.T) {
+	// Friday, 2025-09-19 17:03 in Kyiv.
+	sentAt := time.Date(2025, 9, 19, 17, 3, 0, 0, timezone)
+
+	tests := []struct {
+		name     string
+		date     string
+		want     time.Time
+		resolved bool
+	}{
+		{"no date", "", sentAt, false},
+		{"day keeps time of sending", "2025-09-18", time.Date(2025, 9, 18, 17, 3, 0, 0, timezone), true},
+		{"day and time", "2025-09-15 08:30", time.Date(2025, 9, 15, 8, 30, 0, 0, timezone), true},
+		{"later today", "2025-09-19 20:00", time.Date(2025, 9, 19, 20, 0, 0, 0, timezone), true},
+		{"future day", "2025-09-20", sentAt, false},
+		{"too old", "2023-09-15", sentAt, false},
+		{"unparsable", "yesterday", sentAt, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, resolved := resolveDate(tt.date, sentAt)
+			if !got.Equal(tt.want) || resolved != tt.resolved {
+				t.Errorf("resolveDate(%q) = %s, %v; want %s, %v", tt.date, got, resolved, tt.want, tt.resolved)
+			}
+		})
+	}
+}
+
+func TestCashHandler_RelativeDateUsedInLinkAndEchoed(t *testing.T) {
+	fakes := installAppFakes(t)
+	sentAt := time.Date(2025, 9, 19, 17, 3, 0, 0, timezone)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 1, ChatID: 2, Text: "вчора таксі 230", Date: sentAt}
+	fakes.ai.response = &aiservice.Response{Category: "Transport", Subcategory: "Taxi", Amount: 230, Date: "2025-09-18"}
+
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if !strings.Contains(fakes.ai.userPrompt, "вчора") {
+		t.Fatalf("user prompt = %q, want message text", fakes.ai.userPrompt)
+	}
+	want := time.Date(2025, 9, 18, 17, 3, 0, 0, timezone)
+	if len(fakes.deepLink.calls) != 1 || !fakes.deepLink.calls[0].date.Equal(want) {
+		t.Fatalf("deep link calls = %+v, want date %s", fakes.deepLink.calls, want)
+	}
+	if got := fakes.tasks.scheduledMessages[0].Text; !strings.Contains(got, "\nDate: 2025-09-18 17:03") {
+		t.Fatalf("scheduled text = %q, want resolved date", got)
+	}
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/morph/internal/aiservice"
 	"github.com/morph/internal/botservice"
+	"github.com/morph/internal/offlineai"
 	"github.com/morph/internal/taskservice"
 )
 
@@ -22,6 +24,25 @@ type fakeBot struct {
 	parseCalls    int
 	sentMessages  []taskservice.ScheduledMessage
 	sendCallCount int
+	file          *botservice.File
+	fileErr       error
+	fileIDs       []string
+	nextID        int64
+	sendErr       error
+	edits         []botEdit
+	editErr       error
+	deletions     []botEdit
+	deleteErr     error
+	callbacks     []string
+	updates       [][]byte
+	pollOffset    int64
+}
+
+type botEdit struct {
+	chatID    int64
+	messageID int64
+	text      string
+	parseMode string
 }
 
 func (b *fakeBot) GetChatID() (int64, error) {
@@ -33,25 +54,72 @@ func (b *fakeBot) Parse(body io.ReadCloser) *botservice.BotMessage {
 	return b.message
 }
 
-func (b *fakeBot) SendMessage(chatID int64, text string, replyToMessageID *int64) {
+func (b *fakeBot) SendMessage(chatID int64, text string, parseMode string, replyToMessageID *int64) (int64, error) {
 	b.sendCallCount++
+	if b.sendErr != nil {
+		return 0, b.sendErr
+	}
 	b.sentMessages = append(b.sentMessages, taskservice.ScheduledMessage{
 		ChatID:           chatID,
 		Text:             text,
+		ParseMode:        parseMode,
 		ReplyToMessageID: replyToMessageID,
 	})
+	b.nextID++
+	return 1000 + b.nextID, nil
+}
+
+func (b *fakeBot) EditMessage(chatID int64, messageID int64, text string, parseMode string) error {
+	b.edits = append(b.edits, botEdit{chatID: chatID, messageID: messageID, text: text, parseMode: parseMode})
+	return b.editErr
+}
+
+func (b *fakeBot) DeleteMessage(chatID int64, messageID int64) error {
+	b.deletions = append(b.deletions, botEdit{chatID: chatID, messageID: messageID})
+	return b.deleteErr
+}
+
+func (b *fakeBot) AnswerCallback(callbackID string, text string) error {
+	b.callbacks = append(b.callbacks, callbackID)
+	return nil
+}
+
+// Poll hands out the queued updates, numbered from offset, then returns.
+func (b *fakeBot) Poll(ctx context.Context, offset int64, handle func(updateID int64, update []byte)) error {
+	b.pollOffset = offset
+	for i, update := range b.updates {
+		handle(offset+int64(i), update)
+	}
+	return nil
+}
+
+func (b *fakeBot) DownloadFile(fileID string) (*botservice.File, error) {
+	b.fileIDs = append(b.fileIDs, fileID)
+	return b.file, b.fileErr
 }
 
 type fakeAI struct {
-	response   *aiservice.Response
+	response *aiservice.Response
+	// responses, when set, are returned one per call before response.
+	responses  []*aiservice.Response
+	err        error
 	callCount  int
 	userPrompt string
+	image      *aiservice.Image
 }
 
-func (a *fakeAI) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) *aiservice.Response {
+func (a *fakeAI) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
 	a.callCount++
 	a.userPrompt = userPrompt
-	return a.response
+	if len(a.responses) > 0 {
+		response := a.responses[0]
+		a.responses = a.responses[1:]
+		return response, nil
+	}
+	if a.response == nil && a.err == nil {
+		return nil, errors.New("no response")
+	}
+	return a.response, a.err
 }
 
 type deepLinkCall struct {
@@ -60,6 +128,12 @@ type deepLinkCall struct {
 	account     string
 	amount      float64
 	date        time.Time
+	draft       bool
+}
+
+func (a *fakeAI) RequestImage(name string, description string, systemPrompt string, userPrompt string, image aiservice.Image, ctx *context.Context) (*aiservice.Response, error) {
+	a.image = &image
+	return a.Request(name, description, systemPrompt, userPrompt, ctx)
 }
 
 type fakeDeepLinkGenerator struct {
@@ -69,6 +143,14 @@ type fakeDeepLinkGenerator struct {
 }
 
 func (g *fakeDeepLinkGenerator) Create(category string, subcategory string, account string, amount float64, date time.Time) string {
+	return g.record(category, subcategory, account, amount, date, false)
+}
+
+func (g *fakeDeepLinkGenerator) CreateDraft(category string, subcategory string, account string, amount float64, date time.Time) string {
+	return g.record(category, subcategory, account, amount, date, true)
+}
+
+func (g *fakeDeepLinkGenerator) record(category string, subcategory string, account string, amount float64, date time.Time, draft bool) string {
 	g.callCount++
 	g.calls = append(g.calls, deepLinkCall{
 		category:    category,
@@ -76,6 +158,7 @@ func (g *fakeDeepLinkGenerator) Create(category string, subcategory string, acco
 		account:     account,
 		amount:      amount,
 		date:        date,
+		draft:       draft,
 	})
 	if g.link != "" {
 		return g.link
@@ -100,6 +183,8 @@ type fakeTaskService struct {
 	connectCount          int
 	closeCount            int
 	scheduledMessages     []taskservice.ScheduledMessage
+	scheduledEdits        []taskservice.ScheduledEdit
+	scheduledDeletions    []taskservice.ScheduledDeletion
 	scheduledTransactions []taskservice.ScheduledTransaction
 }
 
@@ -115,16 +200,69 @@ func (s *fakeTaskService) ScheduleMessage(ctx *context.Context, scheduledMessage
 	s.scheduledMessages = append(s.scheduledMessages, scheduledMessage)
 }
 
+func (s *fakeTaskService) ScheduleEdit(ctx *context.Context, scheduledEdit taskservice.ScheduledEdit, timeOffset time.Time) {
+	s.scheduledEdits = append(s.scheduledEdits, scheduledEdit)
+}
+
+func (s *fakeTaskService) ScheduleDeletion(ctx *context.Context, scheduledDeletion taskservice.ScheduledDeletion, timeOffset time.Time) {
+	s.scheduledDeletions = append(s.scheduledDeletions, scheduledDeletion)
+}
+
 func (s *fakeTaskService) ScheduleTransaction(ctx *context.Context, scheduledTransaction taskservice.ScheduledTransaction, timeOffset time.Time) {
 	s.scheduledTransactions = append(s.scheduledTransactions, scheduledTransaction)
 }
 
+type fakeStore struct {
+	values  map[string][]byte
+	loadErr error
+	// afterLoad, when set, runs once after the next successful Load, as if
+	// another instance changed the store right then.
+	afterLoad func()
+}
+
+func (s *fakeStore) Load(key string, value any) (bool, error) {
+	if s.loadErr != nil {
+		return false, s.loadErr
+	}
+	if afterLoad := s.afterLoad; afterLoad != nil {
+		s.afterLoad = nil
+		defer afterLoad()
+	}
+	data, ok := s.values[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, value)
+}
+
+func (s *fakeStore) Save(key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.values[key] = data
+	return nil
+}
+
+type fakeSpeech struct {
+	transcript string
+	err        error
+	audio      []byte
+}
+
+func (s *fakeSpeech) Transcribe(audio []byte, mediaType string, ctx *context.Context) (string, error) {
+	s.audio = audio
+	return s.transcript, s.err
+}
+
 type appFakes struct {
 	bot      *fakeBot
 	ai       *fakeAI
 	shortURL *fakeShortURL
 	deepLink *fakeDeepLinkGenerator
 	tasks    *fakeTaskService
+	store    *fakeStore
+	speech   *fakeSpeech
 }
 
 func installAppFakes(t *testing.T) appFakes {
@@ -135,6 +273,10 @@ func installAppFakes(t *testing.T) appFakes {
 	oldShortURLService := shortURLService
 	oldDeepLinkGenerator := deepLinkGenerator
 	oldTaskService := taskService
+	oldStore := store
+	oldSpeechService := speechService
+	oldOfflineAI := offlineAI
+	oldProfiles := profiles
 
 	fakes := appFakes{
 		bot:      &fakeBot{chatID: 12345},
@@ -142,6 +284,8 @@ func installAppFakes(t *testing.T) appFakes {
 		shortURL: &fakeShortURL{url: "https://short.example/link"},
 		deepLink: &fakeDeepLinkGenerator{link: "moneywiz://expense"},
 		tasks:    &fakeTaskService{},
+		store:    &fakeStore{values: map[string][]byte{}},
+		speech:   &fakeSpeech{},
 	}
 
 	bot = fakes.bot
@@ -149,6 +293,10 @@ func installAppFakes(t *testing.T) appFakes {
 	shortURLService = fakes.shortURL
 	deepLinkGenerator = fakes.deepLink
 	taskService = fakes.tasks
+	store = fakes.store
+	speechService = fakes.speech
+	offlineAI = offlineai.New(fakes.store)
+	profiles = nil
 
 	t.Cleanup(func() {
 		bot = oldBot
@@ -156,6 +304,10 @@ func installAppFakes(t *testing.T) appFakes {
 		shortURLService = oldShortURLService
 		deepLinkGenerator = oldDeepLinkGenerator
 		taskService = oldTaskService
+		store = oldStore
+		speechService = oldSpeechService
+		offlineAI = oldOfflineAI
+		profiles = oldProfiles
 	})
 
 	return fakes
@@ -263,6 +415,45 @@ func TestCashHandler_HappyPathUsesCashEURAndSchedulesShortLink(t *testing.T) {
 	}
 }
 
+func TestCashHandler_SeveralExpensesGetOneReplyWithALinkEach(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.message = &botservice.BotMessage{
+		MessageID: 89,
+		ChatID:    777,
+		Text:      "coffee 65, taxi 230",
+	}
+	fakes.ai.response = &aiservice.Response{
+		Category:      "Food",
+		Subcategory:   "Outdoors",
+		Amount:        65,
+		IsTransaction: true,
+		Expenses: []aiservice.Expense{
+			{Category: "Food", Subcategory: "Outdoors", Amount: 65},
+			{Category: "Transport", Subcategory: "Taxi", Amount: 230},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`))
+	rr := httptest.NewRecorder()
+
+	CashHandler(rr, req)
+
+	if fakes.deepLink.callCount != 2 {
+		t.Fatalf("deep link calls = %d, want 2", fakes.deepLink.callCount)
+	}
+	if fakes.deepLink.calls[1].category != "Transport" || fakes.deepLink.calls[1].amount != 230 {
+		t.Fatalf("second deep link = %+v, want Transport 230", fakes.deepLink.calls[1])
+	}
+	if len(fakes.tasks.scheduledMessages) != 1 {
+		t.Fatalf("scheduled messages = %d, want 1", len(fakes.tasks.scheduledMessages))
+	}
+	wantText := "Category: Food\nSubcategory: Outdoors\nAmount: 65.00\nhttps://short.example/link\n\n" +
+		"Category: Transport\nSubcategory: Taxi\nAmount: 230.00\nhttps://short.example/link"
+	if got := fakes.tasks.scheduledMessages[0].Text; got != wantText {
+		t.Fatalf("scheduled text = %q, want %q", got, wantText)
+	}
+}
+
 func TestCashHandler_ShortURLErrorFallsBackToRawDeepLink(t *testing.T) {
 	fakes := installAppFakes(t)
 	fakes.bot.message = &botservice.BotMessage{
@@ -316,7 +507,7 @@ func TestMonoHandler_InvalidJSONReturnsBadRequest(t *testing.T) {
 func TestMonoHandler_NoAIResponseSchedulesErrorMessage(t *testing.T) {
 	fakes := installAppFakes(t)
 
-	req := httptest.NewRequest(http.MethodPost, "/monoHandler", strings.NewReader(`{"chatId":321,"mcc":4121,"category":"Transport","description":"Bolt","amount":120,"time":1746194127}`))
+	req := httptest.NewRequest(http.MethodPost, "/monoHandler", strings.NewReader(`{"chatId":321,"mcc":5999,"category":"Miscellaneous and speciality retail outlets","description":"Rozetka","amount":120,"time":1746194127}`))
 	rr := httptest.NewRecorder()
 
 	MonoHandler(rr, req)
@@ -495,7 +686,7 @@ func TestMonoWebHook_HappyPathSchedulesTransaction(t *testing.T) {
 	}
 }
 
-func TestMonoWebHook_UnknownMCCSchedulesErrorNotification(t *testing.T) {
+func TestMonoWebHook_UnknownMCCFallsBackToAIAndIsRecorded(t *testing.T) {
 	fakes := installAppFakes(t)
 	fakes.bot.chatID = 432
 
@@ -504,23 +695,118 @@ func TestMonoWebHook_UnknownMCCSchedulesErrorNotification(t *testing.T) {
 
 	MonoWebHook(rr, req)
 
-	if rr.Code != http.StatusInternalServerError {
-		t.Fatalf("status = %d, want %d", rr.Code, http.StatusInternalServerError)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	if len(fakes.tasks.scheduledMessages) != 0 {
+		t.Fatalf("scheduled messages = %d, want 0", len(fakes.tasks.scheduledMessages))
+	}
+	if len(fakes.tasks.scheduledTransactions) != 1 {
+		t.Fatalf("scheduled transactions = %d, want 1", len(fakes.tasks.scheduledTransactions))
+	}
+	got := fakes.tasks.scheduledTransactions[0]
+	if got.ChatID != 432 || got.MCC != 999999 || got.Category != "" || got.Amount != 50 {
+		t.Fatalf("scheduled transaction = %+v, want transaction without MCC category", got)
+	}
+
+	seen := map[int32]unknownMCC{}
+	if found, err := fakes.store.Load(unknownMCCKey, &seen); err != nil || !found {
+		t.Fatalf("unknown MCC list found/err = %v/%v, want stored list", found, err)
+	}
+	if entry := seen[999999]; entry.Count != 1 || entry.Description != "Unknown shop" {
+		t.Fatalf("unknown MCC entry = %+v, want one occurrence at Unknown shop", entry)
+	}
+}
+
+func TestMonoHandler_MappedMCCSkipsAI(t *testing.T) {
+	fakes := installAppFakes(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/monoHandler", strings.NewReader(`{"chatId":654,"mcc":5411,"category":"Groceries and supermarkets","description":"Silpo","amount":250.5,"time":1746194127,"accountId":"a-dnHAO9ExLnboGJP_pdwA"}`))
+	rr := httptest.NewRecorder()
+
+	MonoHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	if fakes.ai.callCount != 0 {
+		t.Fatalf("AI calls = %d, want 0", fakes.ai.callCount)
+	}
+	if len(fakes.deepLink.calls) != 1 {
+		t.Fatalf("deep link calls = %d, want 1", len(fakes.deepLink.calls))
+	}
+	deepLink := fakes.deepLink.calls[0]
+	if deepLink.category != "Food" || deepLink.subcategory != "Shop" || deepLink.amount != 250.5 {
+		t.Fatalf("deep link = %+v, want Food/Shop 250.50", deepLink)
 	}
-	if rr.Body.String() != "Could not get category" {
-		t.Fatalf("body = %q, want category error", rr.Body.String())
+
+	ctx := aiservice.WithInput(context.Background(), "{ mcc: 5411, description: Novus, category: Groceries and supermarkets, amount: 99.00 }")
+	learned, err := offlineAI.Request("Morph", "desc", "system", "Classify this bank transaction", &ctx)
+	if err != nil || learned.Category != "Food" || learned.Subcategory != "Shop" {
+		t.Fatalf("offline model = %+v, %v; want the mapping learned", learned, err)
+	}
+}
+
+func TestMCCReport_SendsUnknownCodesAndResets(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.chatID = 246
+	recordUnknownMCC(1111, "Shop A", time.Unix(1746194127, 0))
+	recordUnknownMCC(2222, "Shop B", time.Unix(1746194127, 0))
+	recordUnknownMCC(2222, "Shop C", time.Unix(1746194128, 0))
+
+	req := httptest.NewRequest(http.MethodPost, "/mccReport", nil)
+	rr := httptest.NewRecorder()
+
+	MCCReport(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
 	}
 	if len(fakes.tasks.scheduledMessages) != 1 {
 		t.Fatalf("scheduled messages = %d, want 1", len(fakes.tasks.scheduledMessages))
 	}
 	got := fakes.tasks.scheduledMessages[0]
-	if got.ChatID != 432 {
-		t.Fatalf("scheduled message chat = %d, want 432", got.ChatID)
+	wantText := "⚠️ MCC codes not found since the last report:\n2222 × 2 (Shop C)\n1111 × 1 (Shop A)"
+	if got.ChatID != 246 || got.Text != wantText {
+		t.Fatalf("scheduled message = %+v, want report %q", got, wantText)
 	}
-	if !strings.Contains(got.Text, "999999") {
-		t.Fatalf("scheduled message text = %q, want MCC code", got.Text)
+
+	rr = httptest.NewRecorder()
+	MCCReport(rr, req)
+	if len(fakes.tasks.scheduledMessages) != 1 {
+		t.Fatalf("scheduled messages after reset = %d, want 1", len(fakes.tasks.scheduledMessages))
+	}
+}
+
+func TestMCCReport_KeepsCodesRecordedWhileReporting(t *testing.T) {
+	fakes := installAppFakes(t)
+	recordUnknownMCC(1111, "Shop A", time.Unix(1746194127, 0))
+	recordUnknownMCC(2222, "Shop B", time.Unix(1746194127, 0))
+	fakes.store.afterLoad = func() {
+		recordUnknownMCC(1111, "Shop A", time.Unix(1746194130, 0))
+		recordUnknownMCC(3333, "Shop D", time.Unix(1746194130, 0))
+	}
+
+	MCCReport(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/mccReport", nil))
+
+	var seen map[int32]unknownMCC
+	fakes.store.Load(unknownMCCKey, &seen)
+	if len(seen) != 2 || seen[1111].Count != 1 || seen[3333].Count != 1 {
+		t.Errorf("unknown codes after the report = %+v, want only the ones recorded meanwhile", seen)
 	}
-	if len(fakes.tasks.scheduledTransactions) != 0 {
-		t.Fatalf("scheduled transactions = %d, want 0", len(fakes.tasks.scheduledTransactions))
+}
+
+func TestRecordUnknownMCC_KeepsTheListWhenItCannotBeLoaded(t *testing.T) {
+	fakes := installAppFakes(t)
+	recordUnknownMCC(1111, "Shop A", time.Unix(1746194127, 0))
+
+	fakes.store.loadErr = errors.New("storage unavailable")
+	recordUnknownMCC(2222, "Shop B", time.Unix(1746194127, 0))
+	fakes.store.loadErr = nil
+
+	var seen map[int32]unknownMCC
+	fakes.store.Load(unknownMCCKey, &seen)
+	if len(seen) != 1 || seen[1111].Count != 1 {
+		t.Errorf("unknown codes = %+v, want the list kept", seen)
 	}
 }
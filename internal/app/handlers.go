@@ -7,26 +7,44 @@ import (
 	"log"
 	"math"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
 	"github.com/morph/internal/category"
+	"github.com/morph/internal/profile"
+	"github.com/morph/internal/prompt"
+	"github.com/morph/internal/render"
 	"github.com/morph/internal/taskservice"
 )
 
+// cashAccountName is the cash wallet used when a message names neither an
+// account nor a currency with a configured wallet.
 const cashAccountName = "CashEUR"
 
-// appendShortLink shortens deepLink and appends the result to text on a new
-// line. If shortening fails it logs the full error and falls back to the raw
-// deep link, so the user still receives a usable link instead of the
-// shortener's (potentially huge) error page.
-func appendShortLink(text, deepLink string) string {
+// shortLink shortens deepLink. If shortening fails it logs the full error and
+// falls back to the raw deep link, so the user still receives a usable link
+// instead of the shortener's (potentially huge) error page.
+func shortLink(deepLink string) render.Link {
 	url, err := shortURLService.Shorten(deepLink)
 	if err != nil {
 		log.Printf("[Morph] Error shortening URL: %v", err)
-		return text + "\n⚠️ Link not shortened:\n" + deepLink
+		return render.Link{URL: deepLink}
 	}
 	log.Printf("[Morph] Shortened URL: %s", url)
-	return text + "\n" + url
+	return render.Link{URL: url, Shortened: true}
+}
+
+// memberName is who sent message in a group chat, shown with the reply so
+// everyone sees whose expense it was: their first and last name, or their
+// username without a first name. Empty in private chats and for senders
+// without either, rather than a bare user ID.
+func memberName(message *botservice.BotMessage) string {
+	if !message.Group {
+		return ""
+	}
+	return message.UserName
 }
 
 func CashHandler(w http.ResponseWriter, r *http.Request) {
@@ -41,7 +59,7 @@ func CashHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Printf("[Morph] Update: %s", message.Text)
 
-	if message.Text == "" {
+	if message.Text == "" && message.FileID == "" && message.VoiceFileID == "" {
 		log.Printf("[Morph] No text in message")
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
@@ -52,63 +70,117 @@ func CashHandler(w http.ResponseWriter, r *http.Request) {
 	taskService.Connect(&ctx)
 	defer taskService.Close()
 
-	categories := category.GetCategoriesInJSON()
-	hints := category.GetHintsInJSON()
-
-	systemPrompt := "You are a data analyst. Your task is to classify the input into a category, subcategory, and amount. You MUST ONLY use the categories and subcategories provided below—do not invent new ones. If the input does not match any, use 'Other' for category and an empty string for subcategory. Output a single-line JSON object with only these fields: category, subcategory, amount. Example of the output: {\"category\": \"Children\", \"subcategory\": \"Vocal\", \"amount\": 400.0}. Categories and subcategories: " + categories + " Hints: " + hints + " IMPORTANT: Do not add any explanation or extra text. Only output the JSON object."
-	userPrompt := "Classify this input: " + message.Text
-
-	response := aiService.Request("Morph", "Translares free input into: Category, Subcategory, Amount", systemPrompt, userPrompt, &ctx)
-	if response == nil {
-		log.Printf("[Morph] No response from AI")
-
-		scheduledMessage := taskservice.ScheduledMessage{
-			ChatID:           message.ChatID,
-			Text:             "No response from AI",
-			ReplyToMessageID: &message.MessageID,
+	if message.Command != "" {
+		// An edited command was answered when it was first sent.
+		if !message.Edited {
+			handleCommand(&ctx, message)
 		}
-
-		taskService.ScheduleMessage(&ctx, scheduledMessage, time.Now())
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 		return
 	}
 
-	absoluteAmount := math.Abs(response.Amount)
+	sentAt := message.Date
+	if sentAt.IsZero() {
+		sentAt = time.Now()
+	}
 
-	log.Printf("[Morph] Response: %s %s %f", response.Category, response.Subcategory, absoluteAmount)
-	text := "Category: " + response.Category + "\nSubcategory: " + response.Subcategory + "\nAmount: " + fmt.Sprintf("%.2f", absoluteAmount)
-	deepLink := deepLinkGenerator.Create(response.Category, response.Subcategory, cashAccountName, absoluteAmount, time.Now())
+	owner := profileForMessage(message)
+	loc := chatLocale(message.ChatID, owner)
 
-	text = appendShortLink(text, deepLink)
+	// Bank SMS and bank bot messages are read like push notifications.
+	if notification, ok := bankText(message); ok && handleBankText(&ctx, owner, loc, message, notification) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+		return
+	}
 
-	log.Printf("[Morph] Sending message to chat %d", message.ChatID)
+	var response *aiservice.Response
+	var transcript string
+	var err error
+	switch {
+	case message.FileID != "":
+		response, err = classifyReceipt(&ctx, owner, message, sentAt)
+	case message.VoiceFileID != "":
+		transcript, err = transcribeVoice(&ctx, message)
+		if err == nil {
+			response, err = classify(&ctx, owner, prompt.SourceCash, transcript, sentAt)
+		}
+	default:
+		response, err = classify(&ctx, owner, prompt.SourceCash, message.Text, sentAt)
+	}
+	if err != nil {
+		log.Printf("[Morph] No response from AI: %v", err)
+		reply(&ctx, message, renderMessage(loc, render.EventError, render.Error{Transcript: transcript, Message: aiErrorMessage(loc, err)}))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+		return
+	}
 
-	scheduledMessage := taskservice.ScheduledMessage{
-		ChatID:           message.ChatID,
-		Text:             text,
-		ReplyToMessageID: &message.MessageID,
+	if message.FileID != "" && !response.IsTransaction {
+		log.Printf("[Morph] Image is not a receipt")
+		reply(&ctx, message, renderMessage(loc, render.EventError, render.Error{Message: loc.Text("not_receipt")}))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+		return
 	}
 
-	taskService.ScheduleMessage(&ctx, scheduledMessage, time.Now())
+	// A message may list several expenses; all of them go into one reply,
+	// each with its own classification and link.
+	expenses := response.Split()
+	entries := make([]render.Entry, 0, len(expenses))
+	summaries := make([]string, 0, len(expenses))
+	for i := range expenses {
+		expense := &expenses[i]
+		absoluteAmount := math.Abs(expense.Amount)
+		account := cashAccountFor(owner.Accounts, message.UserID, expense)
+
+		log.Printf("[Morph] Response: %s %s %f %s", expense.Category, expense.Subcategory, absoluteAmount, account)
+		entry := render.Entry{
+			Category:    expense.Category,
+			Subcategory: expense.Subcategory,
+			Amount:      absoluteAmount,
+			Merchant:    expense.Merchant,
+			Confidence:  expense.Confidence,
+		}
+		if account == "" {
+			entry.NoWallet = strings.ToUpper(expense.Currency)
+		} else if account != cashAccountName {
+			entry.Account = account
+		}
+		// Dates resolved from the message are echoed so they can be checked.
+		date, resolved := resolveDate(expense.Date, sentAt)
+		if resolved {
+			entry.Date = date.In(timezone).Format("2006-01-02 15:04")
+		}
+		entry.Links = classificationLinks(owner, expense, account, absoluteAmount, date)
+		entries = append(entries, entry)
+		summaries = append(summaries, entrySummary(expense.Category, expense.Subcategory, absoluteAmount))
+	}
+	text := renderMessage(loc, render.EventCash, render.Cash{Member: memberName(message), Transcript: transcript, Entries: entries})
+
+	log.Printf("[Morph] Sending message to chat %d", message.ChatID)
+	reply(&ctx, message, text)
+	rememberEntry(message.ChatID, message.MessageID, strings.Join(summaries, ", "))
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 	log.Println("Cash handler finished")
 }
 
-// getAccountNameFromID maps Monobank account IDs to account names for deep links
-func getAccountNameFromID(accountID string) string {
-	accountMap := map[string]string{
-		"a-dnHAO9ExLnboGJP_pdwA": "MonobankUAH",
-		"Llx31dyYA8dahhShny5Vvw": "MonobankUAHWhite",
-		"WKl9I-LztrH1ZWeafLZEzQ": "MonobankEUR",
-		"uHsC3WXdFl0H5CucFXfTHg": "MonobankUSD",
-		"NnyWiNGakLsDRXkTe-EQ9A": "MonoeAid",
-		"9mnHzIA1Fkjn7kmeKiAoGg": "MonoFOPUAH",
-		"uUms_k2kDlN6Uyofrs72gw": "MonoFOPUSD",
-	}
+// monoAccounts are the Monobank accounts of the built-in profile.
+var monoAccounts = map[string]string{
+	"a-dnHAO9ExLnboGJP_pdwA": "MonobankUAH",
+	"Llx31dyYA8dahhShny5Vvw": "MonobankUAHWhite",
+	"WKl9I-LztrH1ZWeafLZEzQ": "MonobankEUR",
+	"uHsC3WXdFl0H5CucFXfTHg": "MonobankUSD",
+	"NnyWiNGakLsDRXkTe-EQ9A": "MonoeAid",
+	"9mnHzIA1Fkjn7kmeKiAoGg": "MonoFOPUAH",
+	"uUms_k2kDlN6Uyofrs72gw": "MonoFOPUSD",
+}
 
+// getAccountNameFromID maps Monobank account IDs to account names for deep links
+func getAccountNameFromID(accountMap map[string]string, accountID string) string {
 	if accountName, ok := accountMap[accountID]; ok {
 		return accountName
 	}
@@ -118,6 +190,29 @@ func getAccountNameFromID(accountID string) string {
 	return "MonobankUAH"
 }
 
+// transactionText is how a Monobank transaction is shown to the AI.
+func transactionText(transaction taskservice.ScheduledTransaction) string {
+	return fmt.Sprintf("{ mcc: %d, description: %s, category: %s, amount: %.2f }", transaction.MCC, transaction.Description, transaction.Category, transaction.Amount)
+}
+
+// classifyTransaction classifies a Monobank transaction from the MCC mapping
+// table when its code is unambiguous and the mapping is in the owner's
+// taxonomy, reporting mapped, and with the AI otherwise.
+func classifyTransaction(ctx *context.Context, owner *profile.Profile, transaction taskservice.ScheduledTransaction) (response *aiservice.Response, mapped bool, err error) {
+	if mapping, ok := category.GetMappingFromMCC(transaction.MCC); ok && owner.Taxonomy().IsValid(mapping.Category, mapping.Subcategory) {
+		log.Printf("[Morph] MCC %d mapped to %s/%s", transaction.MCC, mapping.Category, mapping.Subcategory)
+		return &aiservice.Response{
+			Category:      mapping.Category,
+			Subcategory:   mapping.Subcategory,
+			Amount:        transaction.Amount,
+			IsTransaction: true,
+			Confidence:    1,
+		}, true, nil
+	}
+	response, err = classify(ctx, owner, prompt.SourceMono, transactionText(transaction), time.Time{})
+	return response, false, err
+}
+
 func MonoHandler(w http.ResponseWriter, r *http.Request) {
 	var transaction taskservice.ScheduledTransaction
 	if err := json.NewDecoder(r.Body).Decode(&transaction); err != nil {
@@ -128,23 +223,36 @@ func MonoHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
+	// Until the last attempt, transient AI errors are left to the retries
+	// rather than answered by the offline model.
+	if taskRetriesLeft(r) {
+		ctx = aiservice.WithRetry(ctx)
+	}
 	taskService.Connect(&ctx)
 	defer taskService.Close()
 
-	transactionStr := fmt.Sprintf("{ mcc: %d, description: %s, category: %s, amount: %.2f }", transaction.MCC, transaction.Description, transaction.Category, transaction.Amount)
-	categories := category.GetCategoriesInJSON()
-	hints := category.GetHintsInJSON()
-
-	systemPrompt := "You are a data analyst. Your task is to classify the bank transaction into a category, subcategory, and amount. You MUST ONLY use the categories and subcategories provided below—do not invent new ones. If the input does not match any, use 'Other' for category and an empty string for subcategory. Output a single-line JSON object with only these fields: category, subcategory, amount. Example of the output: {\"category\": \"Children\", \"subcategory\": \"Vocal\", \"amount\": 400.0}. Categories and subcategories: " + categories + " Hints: " + hints + " IMPORTANT: Do not add any explanation or extra text. Only output the JSON object."
-	userPrompt := "Classify this bank transaction: " + transactionStr
-
 	chatId := transaction.ChatID
-	response := aiService.Request("Morph", "Translares Monobank transaction into: Category, Subcategory, Amount", systemPrompt, userPrompt, &ctx)
-	if response == nil {
-		log.Printf("[Morph] No response from AI")
+	owner := profileForTransaction(transaction.Profile, transaction.AccountID)
+	loc := chatLocale(chatId, owner)
+	response, mapped, err := classifyTransaction(&ctx, owner, transaction)
+	if mapped {
+		learnOffline(transactionText(transaction), response)
+	}
+	if err != nil {
+		// Transactions arrive through Cloud Tasks, so transient errors are
+		// retried by failing the task rather than bothering the user.
+		if shouldRetryTask(r, err) {
+			log.Printf("[Morph] Transient AI error, leaving the transaction to be retried: %v", err)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("AI unavailable, retry later"))
+			return
+		}
+
+		log.Printf("[Morph] No response from AI: %v", err)
 		scheduledMessage := taskservice.ScheduledMessage{
 			ChatID:           chatId,
-			Text:             "No response from AI",
+			Text:             renderMessage(loc, render.EventError, render.Error{Message: aiErrorMessage(loc, err)}),
+			ParseMode:        messageFormat.ParseMode(),
 			ReplyToMessageID: nil,
 		}
 		taskService.ScheduleMessage(&ctx, scheduledMessage, time.Now())
@@ -156,10 +264,6 @@ func MonoHandler(w http.ResponseWriter, r *http.Request) {
 	absoluteAmount := math.Abs(response.Amount)
 
 	log.Printf("[Morph] Response: %s %s %f", response.Category, response.Subcategory, absoluteAmount)
-	linkMsg := fmt.Sprintf("Category: %s\nSubcategory: %s\nAmount: %.2f", response.Category, response.Subcategory, absoluteAmount)
-	if transaction.IsRefund {
-		linkMsg += "\n🔄 Refund"
-	}
 
 	// Determine the transaction time. Mono API may provide time in seconds or milliseconds since epoch.
 	// Use a heuristic: treat large values as milliseconds.
@@ -171,21 +275,28 @@ func MonoHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get account name from account ID
-	accountName := getAccountNameFromID(transaction.AccountID)
+	accountName := getAccountNameFromID(owner.Accounts.Mono, transaction.AccountID)
 	log.Printf("[Morph] Account ID: %s, Account Name: %s", transaction.AccountID, accountName)
 
-	deepLink := deepLinkGenerator.Create(response.Category, response.Subcategory, accountName, absoluteAmount, txTime)
-
-	linkMsg = appendShortLink(linkMsg, deepLink)
+	linkMsg := renderMessage(loc, render.EventMono, render.Mono{Entry: render.Entry{
+		Category:    response.Category,
+		Subcategory: response.Subcategory,
+		Amount:      absoluteAmount,
+		Refund:      transaction.IsRefund,
+		Confidence:  response.Confidence,
+		Links:       classificationLinks(owner, response, accountName, absoluteAmount, txTime),
+	}})
 
 	log.Printf("[Morph] Sending message to chat %d", chatId)
 
 	scheduledMessage := taskservice.ScheduledMessage{
 		ChatID:           chatId,
 		Text:             linkMsg,
+		ParseMode:        messageFormat.ParseMode(),
 		ReplyToMessageID: nil,
 	}
 	taskService.ScheduleMessage(&ctx, scheduledMessage, time.Now())
+	rememberEntry(chatId, 0, entrySummary(response.Category, response.Subcategory, absoluteAmount))
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
@@ -59,7 +59,7 @@ func TestGetAccountNameFromID(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getAccountNameFromID(tt.accountID)
+			got := getAccountNameFromID(monoAccounts, tt.accountID)
 			if got != tt.want {
 				t.Errorf("getAccountNameFromID(%q) = %q, want %q", tt.accountID, got, tt.want)
 			}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// lastEntry is the last classification replied in a chat, which /undo retracts.
+type lastEntry struct {
+	// MessageID is the message that was classified, zero for bank transactions.
+	MessageID int64  `json:"messageId"`
+	Summary   string `json:"summary"`
+	At        int64  `json:"at"`
+	Undone    bool   `json:"undone"`
+}
+
+func lastEntryKey(chatID int64) string {
+	return fmt.Sprintf("last_entry_%d", chatID)
+}
+
+func loadLastEntry(chatID int64) (lastEntry, bool) {
+	var entry lastEntry
+	found, err := store.Load(lastEntryKey(chatID), &entry)
+	if err != nil {
+		log.Printf("[Morph] Could not load last entry: %v", err)
+		return entry, false
+	}
+	return entry, found
+}
+
+func saveLastEntry(chatID int64, entry lastEntry) {
+	if err := store.Save(lastEntryKey(chatID), entry); err != nil {
+		log.Printf("[Morph] Could not save last entry: %v", err)
+	}
+}
+
+// rememberEntry records a classification replied in chatID for /undo.
+func rememberEntry(chatID int64, messageID int64, summary string) {
+	saveLastEntry(chatID, lastEntry{MessageID: messageID, Summary: summary, At: time.Now().Unix()})
+}
+
+// entrySummary describes a classification in a few words, e.g. "Food/Shop 12.50".
+func entrySummary(category string, subcategory string, amount float64) string {
+	return fmt.Sprintf("%s/%s %.2f", category, subcategory, amount)
+}
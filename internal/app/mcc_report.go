@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/morph/internal/locale"
+	"github.com/morph/internal/render"
+	"github.com/morph/internal/storage"
+	"github.com/morph/internal/taskservice"
+)
+
+const unknownMCCKey = "unknown_mcc"
+
+// unknownMCC is an MCC code missing from the mcc package, with the last
+// merchant it was seen at so the report has something recognizable.
+type unknownMCC struct {
+	Code        int32  `json:"code"`
+	Count       int    `json:"count"`
+	Description string `json:"description"`
+	LastSeen    int64  `json:"lastSeen"`
+}
+
+// recordUnknownMCC adds an occurrence of code to the persisted unknown MCC
+// list. Transactions of several instances add to the same list, so the
+// change is atomic where the storage allows.
+func recordUnknownMCC(code int32, description string, seenAt time.Time) {
+	var seen map[int32]unknownMCC
+	err := storage.Update(store, unknownMCCKey, &seen, func(found bool) {
+		if seen == nil {
+			seen = map[int32]unknownMCC{}
+		}
+		entry := seen[code]
+		entry.Code = code
+		entry.Count++
+		entry.Description = description
+		entry.LastSeen = seenAt.Unix()
+		seen[code] = entry
+	})
+	if err != nil {
+		log.Printf("[Mono] Could not record unknown MCC code %d: %v", code, err)
+	}
+}
+
+// formatUnknownMCCReport lists the unknown codes, most frequent first, in loc.
+func formatUnknownMCCReport(loc locale.Locale, seen map[int32]unknownMCC) string {
+	entries := make([]unknownMCC, 0, len(seen))
+	for _, entry := range seen {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Code < entries[j].Code
+	})
+
+	digest := render.Digest{Title: loc.Text("mcc.report")}
+	for _, entry := range entries {
+		digest.Lines = append(digest.Lines, fmt.Sprintf("%d × %d (%s)", entry.Code, entry.Count, entry.Description))
+	}
+	return renderMessage(loc, render.EventDigest, digest)
+}
+
+// MCCReport sends the unknown MCC codes seen since the previous report to
+// Telegram and starts a new reporting period. It is triggered on a schedule.
+func MCCReport(w http.ResponseWriter, r *http.Request) {
+	log.Println("[Morph] Started MCC report...")
+
+	owner := defaultProfile()
+	chatID, err := profileChat(owner)
+	if err != nil {
+		log.Printf("[Morph] Error getting chat ID: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Could not get chat ID"))
+		return
+	}
+
+	seen := map[int32]unknownMCC{}
+	if _, err := store.Load(unknownMCCKey, &seen); err != nil {
+		log.Printf("[Morph] Could not load unknown MCC codes: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Could not load unknown MCC codes"))
+		return
+	}
+
+	if len(seen) == 0 {
+		log.Printf("[Morph] No unknown MCC codes to report")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+		return
+	}
+
+	ctx := context.Background()
+	taskService.Connect(&ctx)
+	defer taskService.Close()
+
+	scheduledMessage := taskservice.ScheduledMessage{
+		ChatID:           chatID,
+		Text:             formatUnknownMCCReport(chatLocale(chatID, owner), seen),
+		ParseMode:        messageFormat.ParseMode(),
+		ReplyToMessageID: nil,
+	}
+	taskService.ScheduleMessage(&ctx, scheduledMessage, time.Now())
+
+	// Only the reported occurrences are removed, keeping those recorded since
+	// the list was loaded for the next report.
+	var current map[int32]unknownMCC
+	err = storage.Update(store, unknownMCCKey, &current, func(found bool) {
+		for code, reported := range seen {
+			entry, ok := current[code]
+			if !ok {
+				continue
+			}
+			entry.Count -= reported.Count
+			if entry.Count <= 0 {
+				delete(current, code)
+			} else {
+				current[code] = entry
+			}
+		}
+	})
+	if err != nil {
+		log.Printf("[Morph] Could not reset unknown MCC codes: %v", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+	log.Printf("[Morph] Reported %d unknown MCC codes", len(seen))
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/morph/internal/locale"
+	"github.com/morph/internal/profile"
+	"github.com/morph/internal/render"
+)
+
+// messageFormat is how replies are marked up, read from MORPH_MESSAGE_FORMAT:
+// "text" (default), "html" or "markdownv2".
+var messageFormat = render.ParseFormat(os.Getenv("MORPH_MESSAGE_FORMAT"))
+
+// defaultLocale is the language of the replies, read from MORPH_LOCALE: "en"
+// (default) or "uk". Profiles and chats may choose another.
+var defaultLocale = loadLocale(os.Getenv("MORPH_LOCALE"))
+
+func loadLocale(code string) locale.Locale {
+	if code == "" {
+		return locale.Default
+	}
+	loc, ok := locale.Parse(code)
+	if !ok {
+		log.Printf("[Morph] Unsupported locale %q, using %s", code, loc)
+	}
+	return loc
+}
+
+func chatLocaleKey(chatID int64) string {
+	return fmt.Sprintf("locale_%d", chatID)
+}
+
+// chatLocale is the language of replies in chatID: the one chosen there with
+// /language, else the owner's, else defaultLocale.
+func chatLocale(chatID int64, owner *profile.Profile) locale.Locale {
+	var code string
+	found, err := store.Load(chatLocaleKey(chatID), &code)
+	if err != nil {
+		log.Printf("[Morph] Could not load the locale of chat %d: %v", chatID, err)
+	}
+	if found {
+		if loc, ok := locale.Parse(code); ok {
+			return loc
+		}
+	}
+	if owner.Locale != "" {
+		return loadLocale(owner.Locale)
+	}
+	return defaultLocale
+}
+
+// setChatLocale makes loc the language of replies in chatID.
+func setChatLocale(chatID int64, loc locale.Locale) {
+	if err := store.Save(chatLocaleKey(chatID), string(loc)); err != nil {
+		log.Printf("[Morph] Could not save the locale of chat %d: %v", chatID, err)
+	}
+}
+
+// renderMessage renders the message for event in messageFormat and loc.
+func renderMessage(loc locale.Locale, event string, data any) string {
+	text, _ := render.Render(messageFormat, loc, event, data)
+	return text
+}
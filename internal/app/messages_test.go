@@ -0,0 +1,96 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+	"github.com/morph/internal/locale"
+	"github.com/morph/internal/render"
+)
+
+func TestCashHandler_HTMLFormatEscapesAndSetsParseMode(t *testing.T) {
+	fakes := installAppFakes(t)
+	previous := messageFormat
+	messageFormat = render.HTML
+	t.Cleanup(func() { messageFormat = previous })
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Shop", Amount: 12.5, Merchant: "M&M <Store>"}
+
+	sendCash(fakes, botservice.BotMessage{MessageID: 50, ChatID: 777, Text: "m&m 12.50"})
+	deliver(t, fakes)
+
+	if len(fakes.bot.sentMessages) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(fakes.bot.sentMessages))
+	}
+	sent := fakes.bot.sentMessages[0]
+	if sent.ParseMode != "HTML" {
+		t.Errorf("parse mode = %q, want HTML", sent.ParseMode)
+	}
+	if !strings.Contains(sent.Text, "<b>Merchant:</b> M&amp;M &lt;Store&gt;") || !strings.Contains(sent.Text, `<a href="https://short.example/link">`) {
+		t.Errorf("text = %q", sent.Text)
+	}
+}
+
+func TestHandleCommand_RepliesInPlainText(t *testing.T) {
+	fakes := installAppFakes(t)
+	previous := messageFormat
+	messageFormat = render.MarkdownV2
+	t.Cleanup(func() { messageFormat = previous })
+
+	sendCash(fakes, botservice.BotMessage{MessageID: 51, ChatID: 777, Text: "/help", Command: "help"})
+
+	if len(fakes.tasks.scheduledMessages) != 1 || fakes.tasks.scheduledMessages[0].ParseMode != "" {
+		t.Errorf("scheduled = %+v, want a plain text reply", fakes.tasks.scheduledMessages)
+	}
+}
+
+func TestCashHandler_RepliesInTheProfileLocale(t *testing.T) {
+	fakes := installAppFakes(t)
+	installProfiles(t)
+	profiles[1].Locale = "uk"
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 65}
+
+	sendCash(fakes, botservice.BotMessage{MessageID: 52, ChatID: 222, UserID: "2", Text: "кава 65"})
+
+	want := "Категорія: Їжа\nПідкатегорія: Кафе й ресторани\nСума: 65.00\nhttps://short.example/link"
+	if len(fakes.tasks.scheduledMessages) != 1 || fakes.tasks.scheduledMessages[0].Text != want {
+		t.Fatalf("scheduled = %+v, want %q", fakes.tasks.scheduledMessages, want)
+	}
+	// MoneyWiz knows the categories by their canonical names.
+	if len(fakes.deepLink.calls) != 1 || fakes.deepLink.calls[0].category != "Food" || fakes.deepLink.calls[0].subcategory != "Outdoors" {
+		t.Errorf("deep links = %+v, want Food/Outdoors", fakes.deepLink.calls)
+	}
+}
+
+func TestLanguageCommand_SwitchesTheChat(t *testing.T) {
+	fakes := installAppFakes(t)
+	command := func(args string) string {
+		fakes.tasks.scheduledMessages = nil
+		sendCash(fakes, botservice.BotMessage{MessageID: 53, ChatID: 777, Text: "/language " + args, Command: "language", Args: args})
+		if len(fakes.tasks.scheduledMessages) != 1 {
+			t.Fatalf("scheduled messages = %d, want 1", len(fakes.tasks.scheduledMessages))
+		}
+		return fakes.tasks.scheduledMessages[0].Text
+	}
+
+	if text := command(""); !strings.Contains(text, "Replies are in English") || !strings.Contains(text, "uk (Українська)") {
+		t.Errorf("current language = %q", text)
+	}
+	if text := command("de"); !strings.Contains(text, `Unknown language "de"`) {
+		t.Errorf("unknown language = %q", text)
+	}
+	if text := command("uk"); text != "🌐 Тепер відповідаю мовою: Українська." {
+		t.Errorf("switched = %q", text)
+	}
+	if loc := chatLocale(777, defaultProfile()); loc != locale.Ukrainian {
+		t.Errorf("chat locale = %q, want uk", loc)
+	}
+	if text := command("bogus"); !strings.Contains(text, "Невідома мова") {
+		t.Errorf("reply after switching = %q, want Ukrainian", text)
+	}
+	// Another chat keeps the default.
+	if loc := chatLocale(778, defaultProfile()); loc != locale.English {
+		t.Errorf("other chat locale = %q, want en", loc)
+	}
+}
@@ -17,8 +17,78 @@ func SendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bot.SendMessage(msg.ChatID, msg.Text, msg.ReplyToMessageID)
+	sendMessage(msg)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// sendMessage sends msg and, for a reply, remembers which message it
+// answered, so edits of that message can update the reply.
+func sendMessage(msg taskservice.ScheduledMessage) {
+	messageID, err := bot.SendMessage(msg.ChatID, msg.Text, msg.ParseMode, msg.ReplyToMessageID)
+	if err != nil {
+		log.Printf("[Scheduler] Could not send message to user %d: %v", msg.ChatID, err)
+		return
+	}
 	log.Printf("[Scheduler] Message sent to user: %d", msg.ChatID)
+
+	if msg.ReplyToMessageID != nil {
+		rememberReply(msg.ChatID, *msg.ReplyToMessageID, messageID)
+	}
+}
+
+// EditMessage replaces the text of a message sent earlier. When that fails,
+// e.g. because the message was deleted, the text is sent as a new reply.
+func EditMessage(w http.ResponseWriter, r *http.Request) {
+	var edit taskservice.ScheduledEdit
+	if err := json.NewDecoder(r.Body).Decode(&edit); err != nil {
+		log.Printf("[Morph] Could not parse edit %s", err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Could not parse edit"))
+		return
+	}
+
+	editMessage(edit)
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 }
+
+// editMessage applies edit, sending its text as a new reply when the
+// message can't be edited.
+func editMessage(edit taskservice.ScheduledEdit) {
+	if err := bot.EditMessage(edit.ChatID, edit.MessageID, edit.Text, edit.ParseMode); err != nil {
+		log.Printf("[Scheduler] Could not edit message %d, sending a new one: %v", edit.MessageID, err)
+		sendMessage(taskservice.ScheduledMessage{
+			ChatID:           edit.ChatID,
+			Text:             edit.Text,
+			ParseMode:        edit.ParseMode,
+			ReplyToMessageID: edit.ReplyToMessageID,
+		})
+	} else {
+		log.Printf("[Scheduler] Message %d edited for user: %d", edit.MessageID, edit.ChatID)
+	}
+}
+
+// DeleteMessage deletes a message sent earlier.
+func DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	var deletion taskservice.ScheduledDeletion
+	if err := json.NewDecoder(r.Body).Decode(&deletion); err != nil {
+		log.Printf("[Morph] Could not parse deletion %s", err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Could not parse deletion"))
+		return
+	}
+
+	deleteMessage(deletion)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// deleteMessage applies deletion.
+func deleteMessage(deletion taskservice.ScheduledDeletion) {
+	if err := bot.DeleteMessage(deletion.ChatID, deletion.MessageID); err != nil {
+		log.Printf("[Scheduler] Could not delete message %d: %v", deletion.MessageID, err)
+	} else {
+		log.Printf("[Scheduler] Message %d deleted for user: %d", deletion.MessageID, deletion.ChatID)
+	}
+}
@@ -8,11 +8,18 @@ import (
 	"log"
 	"math"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/morph/internal/category"
+	"unicode"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+	"github.com/morph/internal/locale"
+	"github.com/morph/internal/profile"
+	"github.com/morph/internal/prompt"
+	"github.com/morph/internal/render"
 	"github.com/morph/internal/taskservice"
 )
 
@@ -22,6 +29,14 @@ type notificationRequest struct {
 	Title   string `json:"title"`
 	Message string `json:"message"`
 	Date    string `json:"date"`
+	// Sender names whose Shortcut forwarded the notification, to tell the
+	// profiles apart.
+	Sender string `json:"sender,omitempty"`
+}
+
+// notificationText is how a notification is shown to the AI.
+func notificationText(app string, title string, message string) string {
+	return fmt.Sprintf("App: %s\nTitle: %s\nMessage: %s", app, title, message)
 }
 
 // parseNotificationDate parses an RFC3339 instant, a Unix epoch (seconds or
@@ -67,6 +82,13 @@ func parseNotificationDate(raw string) time.Time {
 // bbvaAccount is the single MoneyWiz account for every BBVA notification.
 const bbvaAccount = "BBVAEur"
 
+// notificationBanks are the accounts of the built-in profile per bank.
+var notificationBanks = map[string]profile.Bank{
+	"bbva":   {Account: bbvaAccount},
+	"pumb":   {Cards: pumbAccounts},
+	"privat": {Cards: privatAccounts},
+}
+
 // pumbAccounts maps the masked account token in a PUMB notification (e.g. "*0451").
 var pumbAccounts = map[string]string{
 	"*0451": "PumbUAHPlatinum",
@@ -93,20 +115,16 @@ var privatAccounts = map[string]string{
 	"5*89": "PrivatEUR",
 }
 
-// resolveAccountName picks the MoneyWiz account from the app and the masked
-// account in the message, falling back to the app name when unrecognized.
-func resolveAccountName(app string, message string) string {
-	switch detectBank(app) {
-	case "bbva":
-		return bbvaAccount
-	case "pumb":
-		if account := matchAccountToken(message, pumbAccounts); account != "" {
-			return account
-		}
-	case "privat":
-		if account := matchAccountToken(message, privatAccounts); account != "" {
-			return account
-		}
+// resolveAccountName picks the profile's MoneyWiz account from the app and
+// the masked account in the message, falling back to the app name when
+// unrecognized.
+func resolveAccountName(p *profile.Profile, app string, message string) string {
+	bank := detectBank(app)
+	if account := p.Accounts.Banks[bank].Account; account != "" {
+		return account
+	}
+	if account, ok := p.Card(bank, message); ok {
+		return account
 	}
 
 	log.Printf("[Morph] Could not resolve account for app %q, using app name", app)
@@ -128,17 +146,6 @@ func detectBank(app string) string {
 	}
 }
 
-// matchAccountToken returns the account whose masked token appears in the message.
-// The "*" in tokens avoids collisions with amounts, balances or dates.
-func matchAccountToken(message string, accounts map[string]string) string {
-	for token, account := range accounts {
-		if strings.Contains(message, token) {
-			return account
-		}
-	}
-	return ""
-}
-
 // NotificationHandler turns a bank push notification into a MoneyWiz deep link
 // and delivers it to Telegram.
 func NotificationHandler(w http.ResponseWriter, r *http.Request) {
@@ -172,8 +179,9 @@ func NotificationHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// The notification carries no chat ID, so resolve it from configuration.
-	chatID, err := bot.GetChatID()
+	// The notification carries no chat ID, so it goes to the chat of its owner.
+	owner := profileForNotification(notification)
+	chatID, err := profileChat(owner)
 	if err != nil {
 		log.Printf("[Morph] Error getting chat ID: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -181,22 +189,20 @@ func NotificationHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	loc := chatLocale(chatID, owner)
+
 	ctx := context.Background()
 	taskService.Connect(&ctx)
 	defer taskService.Close()
 
-	categories := category.GetCategoriesInJSON()
-	hints := category.GetHintsInJSON()
-
-	systemPrompt := "You are a data analyst. Your task is to analyze a bank push notification and classify it into a category, subcategory, and amount. First decide whether the notification represents an actual financial transaction (a debit or credit on an account): set isTransaction to false for anything that is not a transaction, such as promotional or marketing messages, security or login alerts, or general informational messages, and set it to true only for real transactions. You MUST ONLY use the categories and subcategories provided below—do not invent new ones. If the input does not match any, use 'Other' for category and an empty string for subcategory. Extract the transaction amount from the notification text as a number (use 0 when there is no transaction). Output a single-line JSON object with only these fields: category, subcategory, amount, isTransaction. Example of the output: {\"category\": \"Children\", \"subcategory\": \"Vocal\", \"amount\": 400.0, \"isTransaction\": true}. Categories and subcategories: " + categories + " Hints: " + hints + " IMPORTANT: Do not add any explanation or extra text. Only output the JSON object."
-	userPrompt := fmt.Sprintf("Classify this bank push notification.\nApp: %s\nTitle: %s\nMessage: %s", notification.App, notification.Title, notification.Message)
-
-	response := aiService.Request("Morph", "Translates a bank push notification into: Category, Subcategory, Amount", systemPrompt, userPrompt, &ctx)
-	if response == nil {
-		log.Printf("[Morph] No response from AI")
+	txTime := parseNotificationDate(notification.Date)
+	response, entry, err := classifyNotification(&ctx, owner, notification, txTime)
+	if err != nil {
+		log.Printf("[Morph] No response from AI: %v", err)
 		scheduledMessage := taskservice.ScheduledMessage{
 			ChatID:           chatID,
-			Text:             "No response from AI",
+			Text:             renderMessage(loc, render.EventError, render.Error{Message: aiErrorMessage(loc, err)}),
+			ParseMode:        messageFormat.ParseMode(),
 			ReplyToMessageID: nil,
 		}
 		taskService.ScheduleMessage(&ctx, scheduledMessage, time.Now())
@@ -206,35 +212,127 @@ func NotificationHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Promotional, informational and other non-transaction pushes are dropped.
-	if !response.IsTransaction {
+	if entry == nil {
 		log.Printf("[Morph] Notification is not a transaction, ignoring")
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 		return
 	}
 
-	absoluteAmount := math.Abs(response.Amount)
-	accountName := resolveAccountName(notification.App, notification.Message)
-
-	txTime := parseNotificationDate(notification.Date)
-
-	log.Printf("[Morph] Response: %s %s %f (account: %s, date: %s)", response.Category, response.Subcategory, absoluteAmount, accountName, txTime)
-	text := fmt.Sprintf("📲 %s\nCategory: %s\nSubcategory: %s\nAmount: %.2f", notification.App, response.Category, response.Subcategory, absoluteAmount)
-
-	deepLink := deepLinkGenerator.Create(response.Category, response.Subcategory, accountName, absoluteAmount, txTime)
-
-	text = appendShortLink(text, deepLink)
+	text := renderMessage(loc, render.EventNotification, render.Notification{App: notification.App, Entry: *entry})
 
 	log.Printf("[Morph] Sending message to chat %d", chatID)
 
 	scheduledMessage := taskservice.ScheduledMessage{
 		ChatID:           chatID,
 		Text:             text,
+		ParseMode:        messageFormat.ParseMode(),
 		ReplyToMessageID: nil,
 	}
 	taskService.ScheduleMessage(&ctx, scheduledMessage, time.Now())
+	rememberEntry(chatID, 0, entrySummary(response.Category, response.Subcategory, entry.Amount))
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 	log.Println("[Morph] Notification handler finished")
 }
+
+// classifyNotification classifies a bank notification of owner made at
+// txTime. The entry is nil when it is not a transaction.
+func classifyNotification(ctx *context.Context, owner *profile.Profile, notification notificationRequest, txTime time.Time) (*aiservice.Response, *render.Entry, error) {
+	text := notificationText(notification.App, notification.Title, notification.Message)
+	response, err := classify(ctx, owner, prompt.SourceNotification, text, time.Time{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if !response.IsTransaction {
+		return response, nil, nil
+	}
+
+	absoluteAmount := math.Abs(response.Amount)
+	accountName := resolveAccountName(owner, notification.App, notification.Message)
+
+	log.Printf("[Morph] Response: %s %s %f (account: %s, date: %s)", response.Category, response.Subcategory, absoluteAmount, accountName, txTime)
+	return response, &render.Entry{
+		Category:    response.Category,
+		Subcategory: response.Subcategory,
+		Amount:      absoluteAmount,
+		Confidence:  response.Confidence,
+		Links:       classificationLinks(owner, response, accountName, absoluteAmount, txTime),
+	}, nil
+}
+
+// bankTextPrefix matches a bank SMS pasted into the chat, such as
+// "sms: ..." or "bank pumb: ...": a marker word, optionally the bank as one
+// more word, and a colon before the text.
+var bankTextPrefix = regexp.MustCompile(`(?is)^\s*(sms|bank|смс|банк)(?:\s+([^\s:]+))?\s*:\s*(.+)$`)
+
+// minBankTextWords is how many words the text after a bank text prefix needs,
+// so a cash message such as "bank fee: 20" isn't taken for one.
+const minBankTextWords = 3
+
+// smsLike reports whether text reads like a bank SMS: a few words with both
+// letters and an amount.
+func smsLike(text string) bool {
+	return len(strings.Fields(text)) >= minBankTextWords &&
+		strings.IndexFunc(text, unicode.IsLetter) >= 0 &&
+		strings.IndexFunc(text, unicode.IsDigit) >= 0
+}
+
+// bankText reads a Telegram message as a bank text: one forwarded from a
+// bank's bot or channel, or pasted after a bank text prefix. The app is the
+// original sender or the bank named in the prefix.
+func bankText(message *botservice.BotMessage) (notificationRequest, bool) {
+	if message.Text == "" || message.FileID != "" || message.VoiceFileID != "" {
+		return notificationRequest{}, false
+	}
+	if message.ForwardedFrom != "" {
+		return notificationRequest{App: message.ForwardedFrom, Message: message.Text}, true
+	}
+	match := bankTextPrefix.FindStringSubmatch(message.Text)
+	if match == nil || !smsLike(match[3]) {
+		return notificationRequest{}, false
+	}
+	app := match[2]
+	if app == "" {
+		app = match[1]
+	}
+	return notificationRequest{App: app, Message: match[3]}, true
+}
+
+// handleBankText replies to a bank text sent to the bot the way
+// NotificationHandler would deliver it. Texts that are not transactions get
+// a note rather than silence, since the user sent them on purpose, except
+// for forwards from a person: those are left unanswered, reporting false,
+// to be read as a cash message.
+func handleBankText(ctx *context.Context, owner *profile.Profile, loc locale.Locale, message *botservice.BotMessage, notification notificationRequest) bool {
+	log.Printf("[Morph] Bank text from %q", notification.App)
+
+	txTime := message.ForwardedAt
+	if txTime.IsZero() {
+		txTime = message.Date
+	}
+	if txTime.IsZero() {
+		txTime = time.Now()
+	}
+
+	response, entry, err := classifyNotification(ctx, owner, notification, txTime)
+	if err != nil {
+		log.Printf("[Morph] No response from AI: %v", err)
+		reply(ctx, message, renderMessage(loc, render.EventError, render.Error{Message: aiErrorMessage(loc, err)}))
+		return true
+	}
+	if entry == nil && message.ForwardedFromPerson {
+		log.Printf("[Morph] Forward from %q is not a bank text", notification.App)
+		return false
+	}
+	if entry == nil {
+		log.Printf("[Morph] Bank text is not a transaction")
+		reply(ctx, message, renderMessage(loc, render.EventError, render.Error{Message: loc.Text("not_transaction")}))
+		return true
+	}
+
+	reply(ctx, message, renderMessage(loc, render.EventNotification, render.Notification{App: notification.App, Member: memberName(message), Entry: *entry}))
+	rememberEntry(message.ChatID, message.MessageID, entrySummary(response.Category, response.Subcategory, entry.Amount))
+	return true
+}
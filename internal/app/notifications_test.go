@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
 )
 
 func TestNotificationHandler_InvalidJSONReturnsBadRequest(t *testing.T) {
@@ -271,7 +272,8 @@ func TestResolveAccountName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := resolveAccountName(tt.app, tt.message); got != tt.want {
+			p := builtinProfile()
+			if got := resolveAccountName(&p, tt.app, tt.message); got != tt.want {
 				t.Fatalf("resolveAccountName(%q, ...) = %q, want %q", tt.app, got, tt.want)
 			}
 		})
@@ -326,3 +328,86 @@ func TestParseNotificationDate(t *testing.T) {
 		}
 	})
 }
+
+func TestBankText(t *testing.T) {
+	tests := []struct {
+		name    string
+		message botservice.BotMessage
+		want    notificationRequest
+		ok      bool
+	}{
+		{"forwarded", botservice.BotMessage{Text: "Оплата 120 UAH *0451", ForwardedFrom: "ПУМБ"}, notificationRequest{App: "ПУМБ", Message: "Оплата 120 UAH *0451"}, true},
+		{"sms prefix", botservice.BotMessage{Text: "SMS: Pokupka 15.00 EUR"}, notificationRequest{App: "SMS", Message: "Pokupka 15.00 EUR"}, true},
+		{"prefix with bank", botservice.BotMessage{Text: "bank privat: Списання 200 UAH\nкартка 5*85"}, notificationRequest{App: "privat", Message: "Списання 200 UAH\nкартка 5*85"}, true},
+		{"cyrillic prefix", botservice.BotMessage{Text: "смс пумб: Оплата 50 UAH"}, notificationRequest{App: "пумб", Message: "Оплата 50 UAH"}, true},
+		{"cash message", botservice.BotMessage{Text: "coffee 65"}, notificationRequest{}, false},
+		{"prefix inside a word", botservice.BotMessage{Text: "bankomat fee: 20"}, notificationRequest{}, false},
+		{"cash message after a prefix", botservice.BotMessage{Text: "Bank fee: 20"}, notificationRequest{}, false},
+		{"prefix with several words", botservice.BotMessage{Text: "bank transfer fee: 20 uah for the card"}, notificationRequest{}, false},
+		{"forwarded photo", botservice.BotMessage{Text: "lunch", FileID: "photo", ForwardedFrom: "Anna"}, notificationRequest{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := bankText(&tt.message)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("bankText() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCashHandler_ForwardedBankTextIsReadLikeANotification(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Shop", Amount: -120, IsTransaction: true}
+	forwardedAt := time.Date(2025, 9, 19, 10, 0, 0, 0, time.UTC)
+
+	sendCash(fakes, botservice.BotMessage{MessageID: 60, ChatID: 777, Text: "Оплата 120.00 UAH, картка *0451", ForwardedFrom: "ПУМБ", ForwardedAt: forwardedAt})
+
+	if !strings.Contains(fakes.ai.userPrompt, "App: ПУМБ\nTitle: \nMessage: Оплата 120.00 UAH, картка *0451") {
+		t.Errorf("user prompt = %q, want the notification text", fakes.ai.userPrompt)
+	}
+	if len(fakes.deepLink.calls) != 1 || fakes.deepLink.calls[0].account != "PumbUAHPlatinum" || !fakes.deepLink.calls[0].date.Equal(forwardedAt) {
+		t.Errorf("deep links = %+v, want PumbUAHPlatinum at the time of the original", fakes.deepLink.calls)
+	}
+	if len(fakes.tasks.scheduledMessages) != 1 {
+		t.Fatalf("scheduled messages = %d, want 1", len(fakes.tasks.scheduledMessages))
+	}
+	reply := fakes.tasks.scheduledMessages[0]
+	if reply.ReplyToMessageID == nil || *reply.ReplyToMessageID != 60 || !strings.HasPrefix(reply.Text, "📲 ПУМБ\nCategory: Food") {
+		t.Errorf("reply = %+v, want the notification reply to the forward", reply)
+	}
+	if entry, _ := loadLastEntry(777); entry.Summary != "Food/Shop 120.00" || entry.MessageID != 60 {
+		t.Errorf("last entry = %+v", entry)
+	}
+}
+
+func TestCashHandler_BankTextThatIsNotATransaction(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.ai.response = &aiservice.Response{IsTransaction: false}
+
+	sendCash(fakes, botservice.BotMessage{MessageID: 61, ChatID: 777, Text: "sms: Your code is 1234"})
+
+	if len(fakes.deepLink.calls) != 0 {
+		t.Errorf("deep links = %+v, want none", fakes.deepLink.calls)
+	}
+	if len(fakes.tasks.scheduledMessages) != 1 || fakes.tasks.scheduledMessages[0].Text != "🏦 This doesn't look like a bank transaction" {
+		t.Errorf("scheduled = %+v, want a note", fakes.tasks.scheduledMessages)
+	}
+}
+
+func TestCashHandler_ForwardFromAPersonThatIsNotABankTextIsACashMessage(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.ai.responses = []*aiservice.Response{
+		{IsTransaction: false},
+		{Category: "Food", Subcategory: "Outdoors", Amount: 250, IsTransaction: true},
+	}
+
+	sendCash(fakes, botservice.BotMessage{MessageID: 62, ChatID: 777, Text: "lunch 250", ForwardedFrom: "Anna", ForwardedFromPerson: true})
+
+	if !strings.Contains(fakes.ai.userPrompt, "Classify this input: lunch 250") {
+		t.Errorf("user prompt = %q, want the cash prompt", fakes.ai.userPrompt)
+	}
+	if len(fakes.tasks.scheduledMessages) != 1 || !strings.Contains(fakes.tasks.scheduledMessages[0].Text, "Category: Food") {
+		t.Errorf("scheduled = %+v, want the cash reply", fakes.tasks.scheduledMessages)
+	}
+}
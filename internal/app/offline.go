@@ -0,0 +1,49 @@
+package app
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/offlineai"
+)
+
+// offlineLearnConfidence is the confidence from which remote classifications
+// train the offline model.
+const offlineLearnConfidence = 0.8
+
+// offlineAI classifies without a remote API, using a model trained on
+// earlier classifications and MCC mappings.
+var offlineAI = offlineai.New(store)
+
+// offlineFallbackOff reports whether MORPH_AI_OFFLINE_FALLBACK turns the
+// offline model off.
+func offlineFallbackOff() bool {
+	return strings.ToLower(strings.TrimSpace(os.Getenv("MORPH_AI_OFFLINE_FALLBACK"))) == "off"
+}
+
+// withOfflineFallback lets the offline model answer whenever service fails
+// with an error retrying won't fix, unless MORPH_AI_OFFLINE_FALLBACK is "off".
+func withOfflineFallback(service aiservice.AIService) aiservice.AIService {
+	if offlineFallbackOff() {
+		return service
+	}
+	return offlineai.Fallback{Primary: service, Offline: offlineAI}
+}
+
+// withOfflineLearning teaches the offline model the confident answers of
+// service, unless MORPH_AI_OFFLINE_FALLBACK is "off".
+func withOfflineLearning(service aiservice.AIService) aiservice.AIService {
+	if offlineFallbackOff() {
+		return service
+	}
+	return offlineai.Teacher{Service: service, Offline: offlineAI, MinConfidence: offlineLearnConfidence}
+}
+
+// learnOffline teaches the offline model a classification made without the
+// AI, such as an MCC mapping of text.
+func learnOffline(text string, response *aiservice.Response) {
+	offlineAI.Learn(text, *response)
+	log.Printf("[Morph] Offline model learned %s/%s", response.Category, response.Subcategory)
+}
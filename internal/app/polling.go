@@ -0,0 +1,61 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+)
+
+// pollingOffsetKey stores the next update to ask for, so a restarted poller
+// neither replays nor skips updates.
+const pollingOffsetKey = "telegram_polling_offset"
+
+// PollUpdates receives Telegram updates by long polling instead of the
+// webhook and runs each through CashHandler, until ctx is done. It is meant
+// for running locally without a public URL for the webhook, with
+// MORPH_TELEGRAM_POLLING set to "on" so replies are sent from this process
+// too.
+func PollUpdates(ctx context.Context) error {
+	var offset int64
+	if _, err := store.Load(pollingOffsetKey, &offset); err != nil {
+		log.Printf("[Morph] Could not load polling offset: %v", err)
+	}
+	log.Printf("[Morph] Polling for updates from offset %d", offset)
+
+	return bot.Poll(ctx, offset, func(updateID int64, update []byte) {
+		r, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/cashHandler", bytes.NewReader(update))
+		if err != nil {
+			log.Printf("[Morph] Could not create request for update %d: %v", updateID, err)
+			return
+		}
+		w := &statusRecorder{header: http.Header{}, status: http.StatusOK}
+		CashHandler(w, r)
+		if w.status != http.StatusOK {
+			log.Printf("[Morph] Update %d was answered with status %d", updateID, w.status)
+		}
+
+		if err := store.Save(pollingOffsetKey, updateID+1); err != nil {
+			log.Printf("[Morph] Could not save polling offset: %v", err)
+		}
+	})
+}
+
+// statusRecorder is the response writer for polled updates, which have no
+// one to answer; it keeps the status for logging.
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (r *statusRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+}
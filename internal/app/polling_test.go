@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+)
+
+func TestPollUpdates_HandlesEachUpdateAndSavesTheOffset(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 5}
+	fakes.bot.message = &botservice.BotMessage{MessageID: 60, ChatID: 777, Text: "coffee 5"}
+	fakes.bot.updates = [][]byte{[]byte(`{"update_id":40}`), []byte(`{"update_id":41}`)}
+	fakes.store.Save(pollingOffsetKey, int64(40))
+
+	if err := PollUpdates(context.Background()); err != nil {
+		t.Fatalf("PollUpdates: %v", err)
+	}
+
+	if fakes.bot.pollOffset != 40 {
+		t.Errorf("polled from %d, want the saved offset 40", fakes.bot.pollOffset)
+	}
+	if fakes.bot.parseCalls != 2 || len(fakes.tasks.scheduledMessages) != 2 {
+		t.Errorf("parsed %d updates and scheduled %d replies, want 2 each", fakes.bot.parseCalls, len(fakes.tasks.scheduledMessages))
+	}
+	var offset int64
+	if _, err := fakes.store.Load(pollingOffsetKey, &offset); err != nil || offset != 42 {
+		t.Errorf("saved offset = %d, %v, want 42", offset, err)
+	}
+}
+
+func TestNewTaskService_RunsTasksLocallyWhenPolling(t *testing.T) {
+	t.Setenv("MORPH_TELEGRAM_POLLING", "on")
+	if _, ok := newTaskService().(localTasks); !ok {
+		t.Error("Expected tasks to run in process when polling")
+	}
+
+	t.Setenv("MORPH_TELEGRAM_POLLING", "")
+	if _, ok := newTaskService().(localTasks); ok {
+		t.Error("Expected Cloud Tasks without polling")
+	}
+}
+
+func TestPollUpdates_SendsRepliesDirectlyWithLocalTasks(t *testing.T) {
+	fakes := installAppFakes(t)
+	taskService = localTasks{}
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 5}
+	fakes.bot.message = &botservice.BotMessage{MessageID: 60, ChatID: 777, Text: "coffee 5"}
+	fakes.bot.updates = [][]byte{[]byte(`{"update_id":40}`)}
+
+	if err := PollUpdates(context.Background()); err != nil {
+		t.Fatalf("PollUpdates: %v", err)
+	}
+
+	if len(fakes.bot.sentMessages) != 1 || fakes.bot.sentMessages[0].ChatID != 777 {
+		t.Fatalf("sent messages = %+v, want the reply in chat 777", fakes.bot.sentMessages)
+	}
+	if replyID, ok := findReply(777, 60); !ok || replyID != 1001 {
+		t.Errorf("findReply = %d/%v, want the sent reply remembered", replyID, ok)
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"log"
+	"os"
+
+	"github.com/morph/internal/botservice"
+	"github.com/morph/internal/profile"
+)
+
+// profiles are read from the JSON file at MORPH_PROFILES. Without it there
+// is one profile, built from the other settings.
+var profiles = loadProfiles()
+
+func loadProfiles() profile.Profiles {
+	path := os.Getenv("MORPH_PROFILES")
+	if path == "" {
+		return nil
+	}
+	loaded, err := profile.Load(path)
+	if err != nil {
+		log.Printf("[Morph] Could not load profiles, using the default one: %v", err)
+		return nil
+	}
+	log.Printf("[Morph] Loaded %d profiles", len(loaded))
+	return loaded
+}
+
+// allProfiles returns the configured profiles, or the built-in one.
+func allProfiles() profile.Profiles {
+	if len(profiles) > 0 {
+		return profiles
+	}
+	return profile.Profiles{builtinProfile()}
+}
+
+// builtinProfile is the single user configured with MORPH_TELEGRAM_CHAT_ID,
+// MORPH_CASH_ACCOUNTS, MORPH_ACCOUNT_ALIASES and MORPH_MEMBER_ACCOUNTS.
+func builtinProfile() profile.Profile {
+	return profile.Profile{
+		Name: "default",
+		Accounts: profile.Accounts{
+			Mono:    monoAccounts,
+			Banks:   notificationBanks,
+			Cash:    cashAccounts,
+			Aliases: accountAliases,
+			Members: memberAccounts,
+		},
+	}
+}
+
+// defaultProfile owns whatever can't be attributed to anyone, and receives
+// reports such as the budget warning.
+func defaultProfile() *profile.Profile {
+	return &allProfiles()[0]
+}
+
+// profileChat is the chat a profile's bank transactions are sent to.
+func profileChat(p *profile.Profile) (int64, error) {
+	if p.ChatID != 0 {
+		return p.ChatID, nil
+	}
+	return bot.GetChatID()
+}
+
+// profileForMessage returns the profile of the sender of a Telegram message,
+// or of the chat it was sent in.
+func profileForMessage(message *botservice.BotMessage) *profile.Profile {
+	ps := allProfiles()
+	if p, ok := ps.ForTelegramUser(message.UserID); ok {
+		return p
+	}
+	if p, ok := ps.ForChat(message.ChatID); ok {
+		return p
+	}
+	return defaultProfile()
+}
+
+// profileForMonoAccount returns the owner of a Monobank account.
+func profileForMonoAccount(accountID string) *profile.Profile {
+	if p, ok := allProfiles().ForMonoAccount(accountID); ok {
+		return p
+	}
+	log.Printf("[Morph] No profile owns Monobank account %s, using %s", accountID, defaultProfile().Name)
+	return defaultProfile()
+}
+
+// profileForTransaction returns the owner of a scheduled Monobank transaction.
+func profileForTransaction(name string, accountID string) *profile.Profile {
+	if p, ok := allProfiles().Named(name); ok {
+		return p
+	}
+	return profileForMonoAccount(accountID)
+}
+
+// profileForNotification returns the owner of a notification: the profile
+// of its sender, or the one holding the card it names.
+func profileForNotification(notification notificationRequest) *profile.Profile {
+	ps := allProfiles()
+	if p, ok := ps.ForNotificationSender(notification.Sender); ok {
+		return p
+	}
+	if p, ok := ps.ForCard(detectBank(notification.App), notification.Message); ok {
+		return p
+	}
+	return defaultProfile()
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+	"github.com/morph/internal/profile"
+	"github.com/morph/internal/prompt"
+)
+
+// installProfiles configures two profiles: max, who gets whatever can't be
+// attributed, and anna, with her own chat, accounts and taxonomy.
+func installProfiles(t *testing.T) {
+	t.Helper()
+	profiles = profile.Profiles{
+		{
+			Name:          "max",
+			ChatID:        111,
+			TelegramUsers: []int64{1},
+			Accounts:      profile.Accounts{Mono: map[string]string{"acc-max": "MonobankUAH"}},
+		},
+		{
+			Name:                "anna",
+			ChatID:              222,
+			TelegramUsers:       []int64{2},
+			NotificationSenders: []string{"anna"},
+			Accounts: profile.Accounts{
+				Mono:    map[string]string{"acc-anna": "AnnaMonoUAH"},
+				Banks:   map[string]profile.Bank{"pumb": {Cards: map[string]string{"*7777": "AnnaPumb"}}},
+				Cash:    map[string]string{"UAH": "AnnaCashUAH"},
+				Aliases: map[string]string{"card": "AnnaPumb"},
+			},
+			Categories: map[string][]string{"Food": {"Groceries", "Cafe"}, "Other": {}},
+		},
+	}
+}
+
+func TestMonoWebHook_RoutesToTheAccountOwner(t *testing.T) {
+	fakes := installAppFakes(t)
+	installProfiles(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/monoWebHook", strings.NewReader(`{"type":"StatementItem","data":{"account":"acc-anna","statementItem":{"id":"a1","time":1746194127,"description":"Silpo","mcc":5411,"amount":-25050,"balance":1000000,"hold":false}}}`))
+	MonoWebHook(httptest.NewRecorder(), req)
+
+	if len(fakes.tasks.scheduledTransactions) != 1 {
+		t.Fatalf("scheduled transactions = %d, want 1", len(fakes.tasks.scheduledTransactions))
+	}
+	if got := fakes.tasks.scheduledTransactions[0]; got.ChatID != 222 || got.Profile != "anna" {
+		t.Errorf("scheduled transaction = %+v, want anna's chat", got)
+	}
+}
+
+func TestMonoHandler_UsesTheOwnersAccountsAndTaxonomy(t *testing.T) {
+	fakes := installAppFakes(t)
+	installProfiles(t)
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Groceries", Amount: 250.5}
+
+	// 5411 maps to Food/Shop, which anna's taxonomy doesn't have, so the AI decides.
+	req := httptest.NewRequest(http.MethodPost, "/monoHandler", strings.NewReader(`{"chatId":222,"mcc":5411,"description":"Silpo","amount":250.5,"time":1746194127,"accountId":"acc-anna","profile":"anna"}`))
+	MonoHandler(httptest.NewRecorder(), req)
+
+	if fakes.ai.callCount != 1 || !strings.Contains(fakes.ai.userPrompt, "Silpo") {
+		t.Fatalf("AI calls = %d, want the transaction classified by the AI", fakes.ai.callCount)
+	}
+	if len(fakes.deepLink.calls) != 1 || fakes.deepLink.calls[0].account != "AnnaMonoUAH" || fakes.deepLink.calls[0].subcategory != "Groceries" {
+		t.Errorf("deep links = %+v, want Food/Groceries on AnnaMonoUAH", fakes.deepLink.calls)
+	}
+	if len(fakes.tasks.scheduledMessages) != 1 || fakes.tasks.scheduledMessages[0].ChatID != 222 {
+		t.Errorf("scheduled = %+v, want a message to anna's chat", fakes.tasks.scheduledMessages)
+	}
+}
+
+func TestNotificationHandler_RoutesToTheOwner(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"by sender", `{"app":"PUMB","title":"Оплата","message":"Рахунок: *0451 Сільпо 42.00UAH","sender":"Anna"}`},
+		{"by card", `{"app":"PUMB","title":"Оплата","message":"Рахунок: *7777 Сільпо 42.00UAH"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakes := installAppFakes(t)
+			installProfiles(t)
+			fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Groceries", Amount: -42, IsTransaction: true}
+
+			NotificationHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/notificationHandler", strings.NewReader(tt.body)))
+
+			if len(fakes.tasks.scheduledMessages) != 1 || fakes.tasks.scheduledMessages[0].ChatID != 222 {
+				t.Fatalf("scheduled = %+v, want a message to anna's chat", fakes.tasks.scheduledMessages)
+			}
+			if !strings.Contains(fakes.ai.userPrompt, "Сільпо") {
+				t.Errorf("user prompt = %q", fakes.ai.userPrompt)
+			}
+		})
+	}
+}
+
+func TestCashHandler_UsesTheSendersProfile(t *testing.T) {
+	fakes := installAppFakes(t)
+	installProfiles(t)
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Cafe", Amount: 65, Currency: "UAH"}
+
+	sendCash(fakes, botservice.BotMessage{MessageID: 70, ChatID: 500, UserID: "2", Text: "coffee 65 грн"})
+
+	if len(fakes.deepLink.calls) != 1 || fakes.deepLink.calls[0].account != "AnnaCashUAH" {
+		t.Errorf("deep links = %+v, want anna's UAH wallet", fakes.deepLink.calls)
+	}
+	if len(fakes.tasks.scheduledMessages) != 1 || fakes.tasks.scheduledMessages[0].ChatID != 500 {
+		t.Errorf("scheduled = %+v, want the reply in the chat of the message", fakes.tasks.scheduledMessages)
+	}
+
+	renderedFor := func(p *profile.Profile) string {
+		rendered, err := renderPrompt(p, prompt.SourceCash, "coffee 65", time.Now())
+		if err != nil {
+			t.Fatalf("renderPrompt: %v", err)
+		}
+		return rendered.System + rendered.User
+	}
+	anna, _ := profiles.Named("anna")
+	if rendered := renderedFor(anna); !strings.Contains(rendered, "Groceries") || strings.Contains(rendered, `"Transport": [`) || !strings.Contains(rendered, `"card"`) {
+		t.Errorf("anna's prompt has the wrong taxonomy or accounts:\n%s", rendered)
+	}
+}
+
+func TestMCCReport_GoesToTheFirstProfile(t *testing.T) {
+	fakes := installAppFakes(t)
+	installProfiles(t)
+	recordUnknownMCC(1111, "Shop A", time.Now())
+
+	MCCReport(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/mccReport", nil))
+
+	if len(fakes.tasks.scheduledMessages) != 1 || fakes.tasks.scheduledMessages[0].ChatID != 111 {
+		t.Errorf("scheduled = %+v, want the report in max's chat", fakes.tasks.scheduledMessages)
+	}
+}
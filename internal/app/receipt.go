@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+	"github.com/morph/internal/profile"
+	"github.com/morph/internal/prompt"
+)
+
+var (
+	errDownload = errors.New("could not download the file")
+	errNotImage = errors.New("file is not an image")
+)
+
+// classifyReceipt downloads the image attached to message and asks a
+// vision-capable model to classify it, with the caption as a note.
+func classifyReceipt(ctx *context.Context, owner *profile.Profile, message *botservice.BotMessage, sentAt time.Time) (*aiservice.Response, error) {
+	file, err := bot.DownloadFile(message.FileID)
+	if err != nil {
+		log.Printf("[Morph] Could not download file %s: %v", message.FileID, err)
+		return nil, fmt.Errorf("%w: %v", errDownload, err)
+	}
+	if !strings.HasPrefix(file.MediaType, "image/") {
+		log.Printf("[Morph] File %s is %s, not an image", message.FileID, file.MediaType)
+		return nil, errNotImage
+	}
+
+	rendered, err := renderPrompt(owner, prompt.SourceReceipt, message.Text, sentAt)
+	if err != nil {
+		return nil, err
+	}
+
+	image := aiservice.Image{Data: file.Data, MediaType: file.MediaType}
+	response, err := aiservice.RequestImage(aiService, "Morph", rendered.Description, rendered.System, rendered.User, image, ctx)
+	logClassification(rendered, response, err)
+	return response, err
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+)
+
+func TestCashHandler_ReceiptPhotoIsReadByVisionModel(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 5, ChatID: 6, Text: "lunch", FileID: "photo-large"}
+	fakes.bot.file = &botservice.File{Data: []byte("jpeg"), MediaType: "image/jpeg"}
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 312.4, Merchant: "Puzata Hata", IsTransaction: true}
+
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if len(fakes.bot.fileIDs) != 1 || fakes.bot.fileIDs[0] != "photo-large" {
+		t.Fatalf("downloaded files = %v, want photo-large", fakes.bot.fileIDs)
+	}
+	if fakes.ai.image == nil || string(fakes.ai.image.Data) != "jpeg" || fakes.ai.image.MediaType != "image/jpeg" {
+		t.Fatalf("AI image = %+v, want the downloaded photo", fakes.ai.image)
+	}
+	if !strings.Contains(fakes.ai.userPrompt, "Note from the user: lunch") {
+		t.Fatalf("user prompt = %q, want caption note", fakes.ai.userPrompt)
+	}
+	want := "Category: Food\nSubcategory: Outdoors\nAmount: 312.40\nMerchant: Puzata Hata\nhttps://short.example/link"
+	if got := fakes.tasks.scheduledMessages[0].Text; got != want {
+		t.Fatalf("scheduled text = %q, want %q", got, want)
+	}
+}
+
+func TestCashHandler_ReceiptDownloadErrorIsReported(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 5, ChatID: 6, FileID: "photo"}
+	fakes.bot.fileErr = errors.New("not found")
+
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if fakes.ai.callCount != 0 {
+		t.Fatalf("AI calls = %d, want 0", fakes.ai.callCount)
+	}
+	if got := fakes.tasks.scheduledMessages[0].Text; got != "📎 Could not download the file" {
+		t.Fatalf("scheduled text = %q, want download error", got)
+	}
+}
+
+func TestCashHandler_ImageThatIsNotAReceipt(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 5, ChatID: 6, FileID: "photo"}
+	fakes.bot.file = &botservice.File{Data: []byte("png"), MediaType: "image/png"}
+	fakes.ai.response = &aiservice.Response{Category: "Other", IsTransaction: false}
+
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if fakes.deepLink.callCount != 0 {
+		t.Fatalf("deep link calls = %d, want 0", fakes.deepLink.callCount)
+	}
+	if got := fakes.tasks.scheduledMessages[0].Text; got != "🧾 This doesn't look like a receipt" {
+		t.Fatalf("scheduled text = %q, want not a receipt", got)
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morph/internal/airecord"
+	"github.com/morph/internal/botservice"
+)
+
+// The handlers below run against responses recorded from the model, in
+// testdata/ai. A change to the rendered prompts misses the recordings and
+// fails these tests; when the change is intended, record them again with
+// MORPH_AI_RECORD_DIR.
+func installReplay(t *testing.T) appFakes {
+	t.Helper()
+	fakes := installAppFakes(t)
+	aiService = airecord.Replayer{Dir: "testdata/ai"}
+	return fakes
+}
+
+func TestReplay_CashHandler(t *testing.T) {
+	fakes := installReplay(t)
+	fakes.bot.message = &botservice.BotMessage{
+		MessageID: 10,
+		ChatID:    12345,
+		Text:      "coffee 65, taxi to the office 230",
+		Date:      time.Date(2025, 9, 19, 9, 30, 0, 0, timezone),
+	}
+
+	rr := httptest.NewRecorder()
+	CashHandler(rr, httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if len(fakes.tasks.scheduledMessages) != 1 {
+		t.Fatalf("scheduled %d messages, want 1", len(fakes.tasks.scheduledMessages))
+	}
+	got := fakes.tasks.scheduledMessages[0].Text
+	for _, want := range []string{"Category: Food\nSubcategory: Outdoors\nAmount: 65.00", "Category: Transport\nSubcategory: Taxi\nAmount: 230.00"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("reply %q is missing %q", got, want)
+		}
+	}
+}
+
+func TestReplay_MonoHandler(t *testing.T) {
+	fakes := installReplay(t)
+
+	rr := httptest.NewRecorder()
+	MonoHandler(rr, httptest.NewRequest(http.MethodPost, "/monoHandler", strings.NewReader(`{"chatId":321,"mcc":5999,"category":"Miscellaneous and speciality retail outlets","description":"Rozetka","amount":-1299,"time":1746194127}`)))
+
+	if len(fakes.tasks.scheduledMessages) != 1 {
+		t.Fatalf("scheduled %d messages, want 1", len(fakes.tasks.scheduledMessages))
+	}
+	if got := fakes.tasks.scheduledMessages[0].Text; !strings.Contains(got, "Category: Devices\nSubcategory: Accessories\nAmount: 1299.00") {
+		t.Errorf("reply = %q, want the recorded classification", got)
+	}
+}
+
+func TestReplay_NotificationHandler(t *testing.T) {
+	fakes := installReplay(t)
+
+	rr := httptest.NewRecorder()
+	NotificationHandler(rr, httptest.NewRequest(http.MethodPost, "/notificationHandler", strings.NewReader(`{"app":"BBVA","title":"Pago con tarjeta","message":"Has pagado 23,40 € en MERCADONA","date":"2025-09-19T18:05:00+02:00"}`)))
+
+	if len(fakes.tasks.scheduledMessages) != 1 {
+		t.Fatalf("scheduled %d messages, want 1", len(fakes.tasks.scheduledMessages))
+	}
+	if got := fakes.tasks.scheduledMessages[0].Text; !strings.Contains(got, "Category: Food\nSubcategory: Shop\nAmount: 23.40") {
+		t.Errorf("reply = %q, want the recorded classification", got)
+	}
+}
+
+func TestReplay_UnrecordedPromptIsReported(t *testing.T) {
+	fakes := installReplay(t)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 11, ChatID: 12345, Text: "never recorded 10", Date: time.Date(2025, 9, 19, 9, 30, 0, 0, timezone)}
+
+	rr := httptest.NewRecorder()
+	CashHandler(rr, httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if len(fakes.tasks.scheduledMessages) != 1 || fakes.tasks.scheduledMessages[0].Text != "No response from AI" {
+		t.Fatalf("scheduled messages = %+v, want the AI error", fakes.tasks.scheduledMessages)
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/morph/internal/botservice"
+	"github.com/morph/internal/storage"
+	"github.com/morph/internal/taskservice"
+)
+
+// maxRememberedReplies bounds the replies remembered per chat. Editing an
+// older message gets a new reply instead of an edited one.
+const maxRememberedReplies = 200
+
+// sentReply links a user message to the bot reply that answered it.
+type sentReply struct {
+	MessageID int64 `json:"messageId"`
+	ReplyID   int64 `json:"replyId"`
+}
+
+func repliesKey(chatID int64) string {
+	return fmt.Sprintf("replies_%d", chatID)
+}
+
+func loadReplies(chatID int64) []sentReply {
+	var replies []sentReply
+	if _, err := store.Load(repliesKey(chatID), &replies); err != nil {
+		log.Printf("[Morph] Could not load replies: %v", err)
+	}
+	return replies
+}
+
+// rememberReply records that replyID answered messageID in chatID. The
+// sendMessage function remembers replies and the cash handler looks them up,
+// so they live in the shared store, and replies sent at once by several
+// instances are all kept.
+func rememberReply(chatID int64, messageID int64, replyID int64) {
+	var replies []sentReply
+	err := storage.Update(store, repliesKey(chatID), &replies, func(found bool) {
+		replies = append(replies, sentReply{MessageID: messageID, ReplyID: replyID})
+		if len(replies) > maxRememberedReplies {
+			replies = replies[len(replies)-maxRememberedReplies:]
+		}
+	})
+	if err != nil {
+		log.Printf("[Morph] Could not save replies: %v", err)
+	}
+}
+
+// findReply returns the latest bot reply to messageID in chatID.
+func findReply(chatID int64, messageID int64) (int64, bool) {
+	replies := loadReplies(chatID)
+	for i := len(replies) - 1; i >= 0; i-- {
+		if replies[i].MessageID == messageID {
+			return replies[i].ReplyID, true
+		}
+	}
+	return 0, false
+}
+
+// reply answers message with text, rendered in messageFormat. When the user edited a message the bot
+// already answered, an edit of the earlier reply is queued; otherwise a new
+// reply is.
+func reply(ctx *context.Context, message *botservice.BotMessage, text string) {
+	if message.Edited {
+		if replyID, ok := findReply(message.ChatID, message.MessageID); ok {
+			log.Printf("[Morph] Editing reply %d to message %d", replyID, message.MessageID)
+			scheduledEdit := taskservice.ScheduledEdit{
+				ChatID:           message.ChatID,
+				MessageID:        replyID,
+				Text:             text,
+				ParseMode:        messageFormat.ParseMode(),
+				ReplyToMessageID: &message.MessageID,
+			}
+			taskService.ScheduleEdit(ctx, scheduledEdit, time.Now())
+			return
+		}
+	}
+
+	scheduledMessage := taskservice.ScheduledMessage{
+		ChatID:           message.ChatID,
+		Text:             text,
+		ParseMode:        messageFormat.ParseMode(),
+		ReplyToMessageID: &message.MessageID,
+	}
+	taskService.ScheduleMessage(ctx, scheduledMessage, time.Now())
+}
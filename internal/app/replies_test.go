@@ -0,0 +1,189 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+	"github.com/morph/third_party/filestore"
+)
+
+// deliver runs the queued messages and edits the way the task queue would.
+func deliver(t *testing.T, fakes appFakes) {
+	t.Helper()
+	for _, message := range fakes.tasks.scheduledMessages {
+		body, _ := json.Marshal(message)
+		SendMessage(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/sendMessage", bytes.NewReader(body)))
+	}
+	for _, edit := range fakes.tasks.scheduledEdits {
+		body, _ := json.Marshal(edit)
+		EditMessage(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/editMessage", bytes.NewReader(body)))
+	}
+	fakes.tasks.scheduledMessages = nil
+	fakes.tasks.scheduledEdits = nil
+}
+
+func sendCash(fakes appFakes, message botservice.BotMessage) {
+	fakes.bot.message = &message
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+}
+
+func TestCashHandler_EditedMessageEditsTheEarlierReply(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 5}
+	sendCash(fakes, botservice.BotMessage{MessageID: 40, ChatID: 777, Text: "cofee 5"})
+	deliver(t, fakes)
+
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 50}
+	sendCash(fakes, botservice.BotMessage{MessageID: 40, ChatID: 777, Text: "coffee 50", Edited: true})
+
+	if len(fakes.tasks.scheduledMessages) != 0 || len(fakes.tasks.scheduledEdits) != 1 {
+		t.Fatalf("scheduled %d messages and %d edits, want the reply edited instead", len(fakes.tasks.scheduledMessages), len(fakes.tasks.scheduledEdits))
+	}
+	deliver(t, fakes)
+	if len(fakes.bot.edits) != 1 {
+		t.Fatalf("edits = %d, want 1", len(fakes.bot.edits))
+	}
+	edit := fakes.bot.edits[0]
+	if edit.chatID != 777 || edit.messageID != 1001 || !strings.Contains(edit.text, "Amount: 50.00") {
+		t.Errorf("edit = %+v, want reply 1001 with the new amount", edit)
+	}
+	if entry, _ := loadLastEntry(777); entry.Summary != "Food/Outdoors 50.00" {
+		t.Errorf("last entry = %+v, want the corrected one", entry)
+	}
+}
+
+func TestCashHandler_EditedMessageWithoutKnownReplyGetsANewReply(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 50}
+
+	sendCash(fakes, botservice.BotMessage{MessageID: 41, ChatID: 777, Text: "coffee 50", Edited: true})
+
+	if len(fakes.tasks.scheduledEdits) != 0 || len(fakes.tasks.scheduledMessages) != 1 {
+		t.Fatalf("edits = %d, scheduled = %d, want a new reply", len(fakes.tasks.scheduledEdits), len(fakes.tasks.scheduledMessages))
+	}
+}
+
+func TestCashHandler_FailedEditFallsBackToANewReply(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 5}
+	sendCash(fakes, botservice.BotMessage{MessageID: 42, ChatID: 777, Text: "cofee 5"})
+	deliver(t, fakes)
+
+	fakes.bot.editErr = errors.New("message to edit not found")
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 50}
+	sendCash(fakes, botservice.BotMessage{MessageID: 42, ChatID: 777, Text: "coffee 50", Edited: true})
+	deliver(t, fakes)
+
+	if len(fakes.bot.edits) != 1 || len(fakes.bot.sentMessages) != 2 {
+		t.Fatalf("edits = %d, sent = %d, want an attempted edit and a new reply", len(fakes.bot.edits), len(fakes.bot.sentMessages))
+	}
+	if got := fakes.bot.sentMessages[1]; got.ReplyToMessageID == nil || *got.ReplyToMessageID != 42 || !strings.Contains(got.Text, "Amount: 50.00") {
+		t.Errorf("new reply = %+v, want the corrected entry as a reply to 42", got)
+	}
+	if replyID, _ := findReply(777, 42); replyID != 1002 {
+		t.Errorf("reply to 42 = %d, want the new reply 1002", replyID)
+	}
+}
+
+func TestCashHandler_EditedCommandIsIgnored(t *testing.T) {
+	fakes := installAppFakes(t)
+
+	sendCash(fakes, botservice.BotMessage{MessageID: 43, ChatID: 777, Text: "/help", Command: "help", Edited: true})
+
+	if len(fakes.tasks.scheduledMessages) != 0 || len(fakes.tasks.scheduledEdits) != 0 || fakes.ai.callCount != 0 {
+		t.Errorf("expected no reply, got %d messages and %d edits", len(fakes.tasks.scheduledMessages), len(fakes.bot.edits))
+	}
+}
+
+func TestRememberReply_KeepsTheLatestReplies(t *testing.T) {
+	installAppFakes(t)
+
+	for id := int64(1); id <= maxRememberedReplies+5; id++ {
+		rememberReply(777, id, 1000+id)
+	}
+	if _, ok := findReply(777, 1); ok {
+		t.Error("expected the oldest reply to be forgotten")
+	}
+	if replyID, ok := findReply(777, maxRememberedReplies+5); !ok || replyID != 1000+maxRememberedReplies+5 {
+		t.Errorf("latest reply = %d, %v", replyID, ok)
+	}
+	if _, ok := findReply(778, maxRememberedReplies+5); ok {
+		t.Error("expected replies to be kept per chat")
+	}
+}
+
+func TestSendMessage_SendFailureIsNotRemembered(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.sendErr = errors.New("chat not found")
+
+	rr := httptest.NewRecorder()
+	SendMessage(rr, httptest.NewRequest(http.MethodPost, "/sendMessage", strings.NewReader(`{"chatId":123,"text":"hello","reply_to_message_id":456}`)))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	if _, ok := findReply(123, 456); ok {
+		t.Error("expected no reply to be remembered")
+	}
+}
+
+func TestDeleteMessage(t *testing.T) {
+	fakes := installAppFakes(t)
+
+	rr := httptest.NewRecorder()
+	DeleteMessage(rr, httptest.NewRequest(http.MethodPost, "/deleteMessage", strings.NewReader(`{"chatId":777,"messageId":1001}`)))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	if len(fakes.bot.deletions) != 1 || fakes.bot.deletions[0].chatID != 777 || fakes.bot.deletions[0].messageID != 1001 {
+		t.Errorf("deletions = %+v, want message 1001 in chat 777", fakes.bot.deletions)
+	}
+
+	rr = httptest.NewRecorder()
+	DeleteMessage(rr, httptest.NewRequest(http.MethodPost, "/deleteMessage", strings.NewReader(`{invalid`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestEditMessage_InvalidJSONReturnsBadRequest(t *testing.T) {
+	installAppFakes(t)
+
+	rr := httptest.NewRecorder()
+	EditMessage(rr, httptest.NewRequest(http.MethodPost, "/editMessage", strings.NewReader(`{invalid`)))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestRememberReply_KeepsRepliesSentAtOnce(t *testing.T) {
+	installAppFakes(t)
+	t.Setenv("MORPH_STORAGE_DIR", t.TempDir())
+	store = filestore.FileStore{}
+
+	var wg sync.WaitGroup
+	for i := int64(1); i <= 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rememberReply(777, i, 1000+i)
+		}()
+	}
+	wg.Wait()
+
+	for i := int64(1); i <= 10; i++ {
+		if replyID, ok := findReply(777, i); !ok || replyID != 1000+i {
+			t.Errorf("findReply(%d) = %d/%v, want %d", i, replyID, ok, 1000+i)
+		}
+	}
+}
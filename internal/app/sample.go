@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/prompt"
+	"github.com/morph/internal/taskservice"
+)
+
+// Sample is an input as one of the handlers receives it, for tools that run
+// the classification path outside of a request, such as morph-eval.
+type Sample struct {
+	Source prompt.Source `json:"source"`
+	// Text is the message of a cash sample.
+	Text string `json:"text,omitempty"`
+	// Transaction is the Monobank transaction of a mono sample.
+	Transaction *taskservice.ScheduledTransaction `json:"transaction,omitempty"`
+	// App, Title and Message make up a notification sample.
+	App     string `json:"app,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Classify classifies sample the way its handler would for the default
+// profile, MCC mapping included, but without replying or learning from the
+// result.
+func Classify(ctx context.Context, sample Sample) (*aiservice.Response, error) {
+	switch sample.Source {
+	case prompt.SourceCash:
+		return classify(&ctx, defaultProfile(), prompt.SourceCash, sample.Text, time.Now())
+	case prompt.SourceMono:
+		if sample.Transaction == nil {
+			return nil, fmt.Errorf("mono sample has no transaction")
+		}
+		response, _, err := classifyTransaction(&ctx, defaultProfile(), *sample.Transaction)
+		return response, err
+	case prompt.SourceNotification:
+		return classify(&ctx, defaultProfile(), prompt.SourceNotification, notificationText(sample.App, sample.Title, sample.Message), time.Time{})
+	default:
+		return nil, fmt.Errorf("unknown sample source %q", sample.Source)
+	}
+}
+
+// NewAIProvider returns the provider selected by MORPH_AI_PROVIDER on its
+// own, without the budget, cache or offline fallback around it. Responses
+// are recorded to MORPH_AI_RECORD_DIR when it is set.
+func NewAIProvider() aiservice.AIService {
+	provider := newAIProvider(nil)
+	if provider == aiservice.AIService(offlineAI) {
+		return provider
+	}
+	return withRecording(provider)
+}
+
+// SetAIService replaces the AI service used for classification.
+func SetAIService(service aiservice.AIService) {
+	aiService = service
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/prompt"
+	"github.com/morph/internal/taskservice"
+)
+
+func TestClassify_SamplesFollowTheirHandlerPath(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Shop", IsTransaction: true}
+	ctx := context.Background()
+
+	response, err := Classify(ctx, Sample{Source: prompt.SourceNotification, App: "BBVA", Title: "Pago", Message: "Mercadona 20 €"})
+	if err != nil || response.Category != "Food" {
+		t.Fatalf("notification: got %+v, %v", response, err)
+	}
+	if !strings.Contains(fakes.ai.userPrompt, "App: BBVA") {
+		t.Errorf("expected the notification text in the prompt, got %q", fakes.ai.userPrompt)
+	}
+
+	response, err = Classify(ctx, Sample{Source: prompt.SourceMono, Transaction: &taskservice.ScheduledTransaction{MCC: 5541, Amount: -100}})
+	if err != nil || response.Category != "Car" || response.Subcategory != "Fuel" {
+		t.Fatalf("mono: got %+v, %v", response, err)
+	}
+	if fakes.ai.callCount != 1 {
+		t.Errorf("expected the mapped MCC not to reach the AI, got %d calls", fakes.ai.callCount)
+	}
+	if len(fakes.store.values) != 0 {
+		t.Errorf("expected nothing to be learned, got %v", fakes.store.values)
+	}
+
+	if _, err := Classify(ctx, Sample{Source: prompt.SourceMono}); err == nil {
+		t.Error("expected an error for a mono sample without a transaction")
+	}
+	if _, err := Classify(ctx, Sample{Source: "sms"}); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
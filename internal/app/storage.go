@@ -0,0 +1,24 @@
+package app
+
+import (
+	"log"
+	"os"
+
+	"github.com/morph/internal/storage"
+	"github.com/morph/third_party/filestore"
+	"github.com/morph/third_party/gcsstore"
+)
+
+// newStore keeps state in the Cloud Storage bucket MORPH_STORAGE_BUCKET,
+// which every function shares, or else in files in MORPH_STORAGE_DIR. Each
+// Cloud Function has its own temp directory, so deployed functions need the
+// bucket to see each other's state.
+func newStore() storage.Storage {
+	if bucket := os.Getenv("MORPH_STORAGE_BUCKET"); bucket != "" {
+		return gcsstore.GCSStore{Bucket: bucket}
+	}
+	if os.Getenv("CLOUD") == "true" && os.Getenv("MORPH_STORAGE_DIR") == "" {
+		log.Printf("[Morph] MORPH_STORAGE_BUCKET is not set, so state such as the AI budget and reply IDs is not shared between functions")
+	}
+	return filestore.FileStore{}
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/morph/internal/taskservice"
+	"github.com/morph/third_party/googletasks"
+)
+
+// newTaskService queues work through Cloud Tasks, or runs it in this process
+// when MORPH_TELEGRAM_POLLING is "on", since a bot polling locally has no
+// deployed functions for the tasks to reach.
+func newTaskService() taskservice.TaskService {
+	if os.Getenv("MORPH_TELEGRAM_POLLING") == "on" {
+		return localTasks{}
+	}
+	return googletasks.GoogleTasks{}
+}
+
+// localTasks runs each task at once, in order, the way the functions behind
+// the Cloud Tasks queues would. Tasks are not retried.
+type localTasks struct{}
+
+func (tasks localTasks) Connect(ctx *context.Context) {}
+
+func (tasks localTasks) Close() {}
+
+func (tasks localTasks) ScheduleMessage(ctx *context.Context, scheduledMessage taskservice.ScheduledMessage, timeOffset time.Time) {
+	sendMessage(scheduledMessage)
+}
+
+func (tasks localTasks) ScheduleEdit(ctx *context.Context, scheduledEdit taskservice.ScheduledEdit, timeOffset time.Time) {
+	editMessage(scheduledEdit)
+}
+
+func (tasks localTasks) ScheduleDeletion(ctx *context.Context, scheduledDeletion taskservice.ScheduledDeletion, timeOffset time.Time) {
+	deleteMessage(scheduledDeletion)
+}
+
+func (tasks localTasks) ScheduleTransaction(ctx *context.Context, scheduledTransaction taskservice.ScheduledTransaction, timeOffset time.Time) {
+	payload, err := json.Marshal(scheduledTransaction)
+	if err != nil {
+		log.Printf("[Scheduler] Error marshalling payload, %s", err.Error())
+		return
+	}
+	r, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/monoHandler", bytes.NewReader(payload))
+	if err != nil {
+		log.Printf("[Scheduler] Could not create request for transaction: %v", err)
+		return
+	}
+	// Nothing retries the transaction, so it is handled as the last attempt.
+	r.Header.Set("X-CloudTasks-TaskRetryCount", strconv.Itoa(maxAIRetries))
+	w := &statusRecorder{header: http.Header{}, status: http.StatusOK}
+	MonoHandler(w, r)
+	if w.status != http.StatusOK {
+		log.Printf("[Scheduler] Transaction was answered with status %d", w.status)
+	}
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+	"github.com/morph/internal/budget"
+	"github.com/morph/internal/speechservice"
+	"github.com/morph/third_party/openai"
+)
+
+var (
+	errSpeechUnsupported = errors.New("speech-to-text is off")
+	errTranscription     = errors.New("could not transcribe the voice message")
+)
+
+// newSpeechService picks the speech-to-text backend from MORPH_STT_PROVIDER:
+//   - "openai" (default): the OpenAI transcriptions API
+//   - "openai-compatible": any server with the same API at MORPH_STT_BASE_URL,
+//     e.g. a local Whisper server
+//   - "off": voice notes are not accepted
+//
+// MORPH_STT_KEY defaults to MORPH_AI_KEY. MORPH_STT_MODEL and
+// MORPH_STT_LANGUAGE (an ISO 639-1 hint such as "uk") are optional.
+// Transcriptions count towards the monthly AI budget and stop with it.
+func newSpeechService() speechservice.SpeechService {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("MORPH_STT_PROVIDER")))
+	config := openai.TranscriberConfig{
+		APIKey:   os.Getenv("MORPH_STT_KEY"),
+		Model:    os.Getenv("MORPH_STT_MODEL"),
+		Language: os.Getenv("MORPH_STT_LANGUAGE"),
+		Timeout:  defaultAITimeout,
+		Recorder: aiBudget,
+	}
+	if config.APIKey == "" {
+		config.APIKey = os.Getenv("MORPH_AI_KEY")
+	}
+
+	switch provider {
+	case "", "openai":
+	case "openai-compatible":
+		config.BaseURL = os.Getenv("MORPH_STT_BASE_URL")
+	case "off":
+		return nil
+	default:
+		log.Printf("[Morph] Unknown speech-to-text provider %q, using OpenAI", provider)
+	}
+	return budget.SpeechGuard{Service: openai.NewTranscriber(config), Budget: aiBudget}
+}
+
+// transcribeVoice downloads the voice note attached to message and returns
+// what was said in it.
+func transcribeVoice(ctx *context.Context, message *botservice.BotMessage) (string, error) {
+	if speechService == nil {
+		return "", errSpeechUnsupported
+	}
+
+	file, err := bot.DownloadFile(message.VoiceFileID)
+	if err != nil {
+		log.Printf("[Morph] Could not download voice note %s: %v", message.VoiceFileID, err)
+		return "", fmt.Errorf("%w: %v", errDownload, err)
+	}
+
+	transcript, err := speechService.Transcribe(file.Data, file.MediaType, ctx)
+	if errors.Is(err, aiservice.ErrBudgetExceeded) {
+		return "", err
+	}
+	if err != nil {
+		log.Printf("[Morph] Could not transcribe voice note %s: %v", message.VoiceFileID, err)
+		return "", fmt.Errorf("%w: %w", errTranscription, err)
+	}
+	transcript = strings.TrimSpace(transcript)
+	if transcript == "" {
+		return "", fmt.Errorf("%w: empty transcript", errTranscription)
+	}
+
+	log.Printf("[Morph] Transcript: %s", transcript)
+	return transcript, nil
+}
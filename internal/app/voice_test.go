@@ -0,0 +1,77 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/botservice"
+)
+
+func TestCashHandler_VoiceNoteIsTranscribedAndEchoed(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 7, ChatID: 8, VoiceFileID: "voice"}
+	fakes.bot.file = &botservice.File{Data: []byte("OggS"), MediaType: "application/ogg"}
+	fakes.speech.transcript = " кава сорок гривень "
+	fakes.ai.response = &aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 40}
+
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if len(fakes.bot.fileIDs) != 1 || fakes.bot.fileIDs[0] != "voice" || string(fakes.speech.audio) != "OggS" {
+		t.Fatalf("downloaded %v and transcribed %q, want the voice note", fakes.bot.fileIDs, fakes.speech.audio)
+	}
+	if !strings.Contains(fakes.ai.userPrompt, "кава сорок гривень") {
+		t.Fatalf("user prompt = %q, want transcript", fakes.ai.userPrompt)
+	}
+	want := "🎙 \"кава сорок гривень\"\n\nCategory: Food\nSubcategory: Outdoors\nAmount: 40.00\nhttps://short.example/link"
+	if got := fakes.tasks.scheduledMessages[0].Text; got != want {
+		t.Fatalf("scheduled text = %q, want %q", got, want)
+	}
+}
+
+func TestCashHandler_VoiceNoteTranscriptionError(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 7, ChatID: 8, VoiceFileID: "voice"}
+	fakes.bot.file = &botservice.File{Data: []byte("OggS"), MediaType: "application/ogg"}
+	fakes.speech.err = fmt.Errorf("transcription failed: %w", aiservice.ErrTimeout)
+
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if fakes.ai.callCount != 0 {
+		t.Fatalf("AI calls = %d, want 0", fakes.ai.callCount)
+	}
+	if got := fakes.tasks.scheduledMessages[0].Text; got != "🎙 Could not transcribe the voice message" {
+		t.Fatalf("scheduled text = %q, want transcription error", got)
+	}
+}
+
+func TestCashHandler_VoiceNoteWithSpeechOff(t *testing.T) {
+	fakes := installAppFakes(t)
+	speechService = nil
+	fakes.bot.message = &botservice.BotMessage{MessageID: 7, ChatID: 8, VoiceFileID: "voice"}
+
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if len(fakes.bot.fileIDs) != 0 {
+		t.Fatalf("downloaded files = %v, want none", fakes.bot.fileIDs)
+	}
+	if got := fakes.tasks.scheduledMessages[0].Text; got != "🎙 Voice messages are not set up" {
+		t.Fatalf("scheduled text = %q, want speech off message", got)
+	}
+}
+
+func TestCashHandler_VoiceNoteOverBudget(t *testing.T) {
+	fakes := installAppFakes(t)
+	fakes.bot.message = &botservice.BotMessage{MessageID: 7, ChatID: 8, VoiceFileID: "voice"}
+	fakes.bot.file = &botservice.File{Data: []byte("OggS"), MediaType: "application/ogg"}
+	fakes.speech.err = aiservice.ErrBudgetExceeded
+
+	CashHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cashHandler", strings.NewReader(`{}`)))
+
+	if got := fakes.tasks.scheduledMessages[0].Text; got != "💸 AI budget for this month is used up" {
+		t.Fatalf("scheduled text = %q, want the budget message", got)
+	}
+}
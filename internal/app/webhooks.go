@@ -2,13 +2,14 @@ package app
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log"
 	"net/http"
-	"strings"
 	"time"
 
+	"github.com/maximbilan/mcc"
 	"github.com/morph/internal/category"
+	"github.com/morph/internal/locale"
 	"github.com/morph/internal/taskservice"
 	"github.com/morph/third_party/mono"
 )
@@ -18,6 +19,7 @@ type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
 	chatID     int64
+	locale     locale.Locale
 	notified   bool
 	ctx        *context.Context
 }
@@ -26,7 +28,7 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	if code == http.StatusInternalServerError && !rw.notified && rw.chatID != 0 && rw.ctx != nil {
 		// Schedule general 500 error notification
-		errorMessage := "❌ [Mono] POST 500 error: Internal server error occurred"
+		errorMessage := rw.locale.Text("mono.server_error")
 		scheduledMessage := taskservice.ScheduledMessage{
 			ChatID:           rw.chatID,
 			Text:             errorMessage,
@@ -49,8 +51,10 @@ func MonoWebHook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get chat ID early so we can send error notifications
-	chatID, err := bot.GetChatID()
+	// Get chat ID early so we can send error notifications. Until the
+	// account's owner is known, they go to the default profile.
+	owner := defaultProfile()
+	chatID, err := profileChat(owner)
 	if err != nil {
 		log.Printf("[Mono] Error getting chat ID: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -68,6 +72,7 @@ func MonoWebHook(w http.ResponseWriter, r *http.Request) {
 		ResponseWriter: w,
 		statusCode:     http.StatusOK,
 		chatID:         chatID,
+		locale:         chatLocale(chatID, owner),
 		notified:       false,
 		ctx:            &ctx,
 	}
@@ -87,26 +92,24 @@ func MonoWebHook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The transaction goes to the chat of the account's owner.
+	owner = profileForMonoAccount(payload.Data.Account)
+	if chatID, err = profileChat(owner); err != nil {
+		log.Printf("[Mono] Error getting chat ID of %s: %v", owner.Name, err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte("Could not get chat ID"))
+		return
+	}
+	rw.chatID = chatID
+	rw.locale = chatLocale(chatID, owner)
+
+	// An MCC the mcc package doesn't know is not fatal: the transaction goes
+	// on without an MCC category and the AI classifies it from the description.
 	mmcCategory, err := category.GetCategoryFromMCC(payload.Data.StatementItem.MCC)
 	if err != nil {
-		log.Printf("[Mono] Error getting category: %v", err)
-		
-		// Check if it's an MCC code not found error
-		errMsg := err.Error()
-		if strings.Contains(errMsg, "MCC code not found") || strings.Contains(errMsg, "mcc code not found") {
-			mccCode := payload.Data.StatementItem.MCC
-			errorMessage := fmt.Sprintf("⚠️ MCC code not found: %d", mccCode)
-			scheduledMessage := taskservice.ScheduledMessage{
-				ChatID:           chatID,
-				Text:             errorMessage,
-				ReplyToMessageID: nil,
-			}
-			taskService.ScheduleMessage(&ctx, scheduledMessage, time.Now())
-			rw.notified = true // Mark as notified to avoid duplicate notification
-			log.Printf("[Mono] Scheduled Telegram notification for missing MCC code: %d", mccCode)
-		} else {
-			// For other category errors, schedule a general error notification
-			errorMessage := fmt.Sprintf("❌ [Mono] Error getting category: %v", err)
+		if !errors.Is(err, mcc.ErrNotFound) {
+			log.Printf("[Mono] Error getting category: %v", err)
+			errorMessage := rw.locale.Text("mono.category_error", err)
 			scheduledMessage := taskservice.ScheduledMessage{
 				ChatID:           chatID,
 				Text:             errorMessage,
@@ -115,11 +118,15 @@ func MonoWebHook(w http.ResponseWriter, r *http.Request) {
 			taskService.ScheduleMessage(&ctx, scheduledMessage, time.Now())
 			rw.notified = true // Mark as notified to avoid duplicate notification
 			log.Printf("[Mono] Scheduled Telegram notification for category error")
+			rw.WriteHeader(http.StatusInternalServerError)
+			rw.Write([]byte("Could not get category"))
+			return
 		}
-		
-		rw.WriteHeader(http.StatusInternalServerError)
-		rw.Write([]byte("Could not get category"))
-		return
+
+		mccCode := payload.Data.StatementItem.MCC
+		log.Printf("[Mono] MCC code not found: %d, falling back to AI-only classification", mccCode)
+		recordUnknownMCC(mccCode, payload.Data.StatementItem.Description, time.Now())
+		mmcCategory = ""
 	}
 
 	scheduledTransaction := taskservice.ScheduledTransaction{
@@ -131,6 +138,7 @@ func MonoWebHook(w http.ResponseWriter, r *http.Request) {
 		Time:        payload.Data.StatementItem.Time,
 		IsRefund:    payload.Data.StatementItem.IsRefund(),
 		AccountID:   payload.Data.Account,
+		Profile:     owner.Name,
 	}
 
 	taskService.ScheduleTransaction(&ctx, scheduledTransaction, time.Now())
@@ -1,16 +1,70 @@
 package botservice
 
-import "io"
+import (
+	"context"
+	"io"
+	"time"
+)
 
 type BotMessage struct {
 	MessageID int64
 	UserID    string
-	ChatID    int64
-	Text      string
+	// UserName is how the sender is called, e.g. "Anna Koval": their first
+	// and last name, or their username without a first name.
+	UserName string
+	ChatID   int64
+	// Group is set in chats shared with other people, where the bot only
+	// gets the commands and the messages that mention it, without the
+	// mention.
+	Group bool
+	Text  string
+	// Date is when the message was sent; zero when the update has no date.
+	Date time.Time
+	// FileID identifies an attached image, such as a receipt photo, to be
+	// fetched with DownloadFile. Text then holds the caption, if any.
+	FileID string
+	// VoiceFileID identifies an attached voice note to be transcribed.
+	VoiceFileID string
+	// Command is the bot command the message starts with, e.g. "help" for
+	// "/help@MorphBot", and Args the text after it. Empty for other messages.
+	Command string
+	Args    string
+	// Edited is set when the user edited a message sent earlier, which
+	// MessageID then identifies.
+	Edited bool
+	// ForwardedFrom names the original sender of a forwarded message, such
+	// as a bank's bot, and ForwardedAt is when they sent it. Empty for
+	// messages that were not forwarded.
+	ForwardedFrom string
+	ForwardedAt   time.Time
+	// ForwardedFromPerson is set when the original sender is a person rather
+	// than a bot or channel, so the forward may be an expense as well as a
+	// bank text.
+	ForwardedFromPerson bool
+}
+
+// File is a downloaded attachment.
+type File struct {
+	Data      []byte
+	MediaType string
 }
 
 type BotService interface {
 	GetChatID() (int64, error)
 	Parse(body io.ReadCloser) *BotMessage
-	SendMessage(chatID int64, text string, replyToMessageID *int64)
+	// SendMessage sends text and returns the ID of the sent message.
+	// parseMode is the Telegram parse mode of text, empty for plain text.
+	SendMessage(chatID int64, text string, parseMode string, replyToMessageID *int64) (int64, error)
+	// EditMessage replaces the text of a message sent earlier.
+	EditMessage(chatID int64, messageID int64, text string, parseMode string) error
+	// DeleteMessage deletes a message sent earlier.
+	DeleteMessage(chatID int64, messageID int64) error
+	// AnswerCallback acknowledges a button press, showing text, if any, as a
+	// notification to the user.
+	AnswerCallback(callbackID string, text string) error
+	DownloadFile(fileID string) (*File, error)
+	// Poll receives updates by polling instead of a webhook, from offset on,
+	// and passes each to handle in the form Parse reads. It returns once ctx
+	// is done.
+	Poll(ctx context.Context, offset int64, handle func(updateID int64, update []byte)) error
 }
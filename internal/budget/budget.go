@@ -0,0 +1,187 @@
+package budget
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/speechservice"
+	"github.com/morph/internal/storage"
+)
+
+// Price is the cost in USD per million tokens, and per minute of audio for
+// speech-to-text models.
+type Price struct {
+	Prompt     float64
+	Completion float64
+	Audio      float64
+}
+
+// prices are matched by model prefix, longest first, so dated snapshots
+// (e.g. "gpt-4o-2024-08-06") share the price of their family. Models not
+// listed here, such as local ones, are counted as free.
+var prices = map[string]Price{
+	"gpt-4o":            {Prompt: 2.50, Completion: 10.00},
+	"gpt-4o-mini":       {Prompt: 0.15, Completion: 0.60},
+	"gpt-4.1":           {Prompt: 2.00, Completion: 8.00},
+	"gpt-4.1-mini":      {Prompt: 0.40, Completion: 1.60},
+	"gpt-4.1-nano":      {Prompt: 0.10, Completion: 0.40},
+	"claude-sonnet-4-5": {Prompt: 3.00, Completion: 15.00},
+	"claude-haiku-4-5":  {Prompt: 1.00, Completion: 5.00},
+	// Transcription models report either tokens or the audio length.
+	"whisper-1":              {Audio: 0.006},
+	"gpt-4o-transcribe":      {Prompt: 6.00, Completion: 10.00, Audio: 0.006},
+	"gpt-4o-mini-transcribe": {Prompt: 3.00, Completion: 5.00, Audio: 0.003},
+}
+
+// EstimateCost returns the estimated USD cost of usage.
+func EstimateCost(usage aiservice.Usage) float64 {
+	price, matched := Price{}, ""
+	for model, candidate := range prices {
+		if strings.HasPrefix(usage.Model, model) && len(model) > len(matched) {
+			price, matched = candidate, model
+		}
+	}
+	if matched == "" {
+		log.Printf("[Budget] No price for model %s, counting it as free", usage.Model)
+	}
+	tokens := (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1_000_000
+	return tokens + usage.AudioSeconds/60*price.Audio
+}
+
+// Month is the AI usage accumulated over one calendar month.
+type Month struct {
+	Month            string  `json:"month"`
+	Calls            int64   `json:"calls"`
+	PromptTokens     int64   `json:"promptTokens"`
+	CompletionTokens int64   `json:"completionTokens"`
+	Cost             float64 `json:"cost"`
+	Warned           bool    `json:"warned"`
+}
+
+// Budget accumulates AI usage per calendar month in storage and reports when
+// the monthly limit is reached. A zero limit only tracks usage.
+type Budget struct {
+	storage    storage.Storage
+	limit      float64
+	onExceeded func(month Month, limit float64)
+	now        func() time.Time
+	mutex      sync.Mutex
+}
+
+// New creates a budget. onExceeded is called once per month, on the call that
+// crosses the limit.
+func New(storage storage.Storage, limit float64, onExceeded func(month Month, limit float64)) *Budget {
+	return &Budget{
+		storage:    storage,
+		limit:      limit,
+		onExceeded: onExceeded,
+		now:        time.Now,
+	}
+}
+
+func (budget *Budget) key() string {
+	return "ai_usage_" + budget.now().UTC().Format("2006-01")
+}
+
+func (budget *Budget) load() Month {
+	month := Month{Month: budget.now().UTC().Format("2006-01")}
+	if _, err := budget.storage.Load(budget.key(), &month); err != nil {
+		log.Printf("[Budget] Could not load usage: %v", err)
+	}
+	return month
+}
+
+// Current returns the usage of the current month.
+func (budget *Budget) Current() Month {
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+	return budget.load()
+}
+
+// Record adds usage to the current month.
+func (budget *Budget) Record(usage aiservice.Usage) {
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+
+	cost := EstimateCost(usage)
+	var month Month
+	var crossed bool
+	// Every function instance adds to the same total, so the change is
+	// atomic where the storage allows.
+	err := storage.Update(budget.storage, budget.key(), &month, func(found bool) {
+		month.Month = budget.now().UTC().Format("2006-01")
+		month.Calls++
+		month.PromptTokens += usage.PromptTokens
+		month.CompletionTokens += usage.CompletionTokens
+		month.Cost += cost
+
+		crossed = budget.limit > 0 && month.Cost >= budget.limit && !month.Warned
+		if crossed {
+			month.Warned = true
+		}
+	})
+	if err != nil {
+		log.Printf("[Budget] Could not save usage: %v", err)
+	}
+
+	log.Printf("[Budget] %s: %d prompt + %d completion tokens, $%.4f (month: $%.2f of $%.2f)", usage.Model, usage.PromptTokens, usage.CompletionTokens, cost, month.Cost, budget.limit)
+
+	if crossed && budget.onExceeded != nil {
+		budget.onExceeded(month, budget.limit)
+	}
+}
+
+// Limit is the monthly limit in USD, zero when usage is only tracked.
+func (budget *Budget) Limit() float64 {
+	return budget.limit
+}
+
+// Exceeded reports whether this month's usage reached the limit.
+func (budget *Budget) Exceeded() bool {
+	if budget.limit <= 0 {
+		return false
+	}
+	return budget.Current().Cost >= budget.limit
+}
+
+// Guard stops calling the AI once the budget is exceeded, leaving
+// classification to the MCC rules and the cache until the next month.
+type Guard struct {
+	Service aiservice.AIService
+	Budget  *Budget
+}
+
+func (guard Guard) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	if guard.Budget.Exceeded() {
+		log.Printf("[Budget] Monthly AI budget exceeded, skipping AI request")
+		return nil, aiservice.ErrBudgetExceeded
+	}
+	return guard.Service.Request(name, description, systemPrompt, userPrompt, ctx)
+}
+
+func (guard Guard) RequestImage(name string, description string, systemPrompt string, userPrompt string, image aiservice.Image, ctx *context.Context) (*aiservice.Response, error) {
+	if guard.Budget.Exceeded() {
+		log.Printf("[Budget] Monthly AI budget exceeded, skipping AI image request")
+		return nil, aiservice.ErrBudgetExceeded
+	}
+	return aiservice.RequestImage(guard.Service, name, description, systemPrompt, userPrompt, image, ctx)
+}
+
+// SpeechGuard stops transcribing voice notes once the budget is exceeded,
+// like Guard does for classifications.
+type SpeechGuard struct {
+	Service speechservice.SpeechService
+	Budget  *Budget
+}
+
+func (guard SpeechGuard) Transcribe(audio []byte, mediaType string, ctx *context.Context) (string, error) {
+	if guard.Budget.Exceeded() {
+		log.Printf("[Budget] Monthly AI budget exceeded, skipping transcription")
+		return "", aiservice.ErrBudgetExceeded
+	}
+	return guard.Service.Transcribe(audio, mediaType, ctx)
+}
@@ -0,0 +1,150 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/third_party/filestore"
+)
+
+type countingAI struct {
+	calls int
+}
+
+func (a *countingAI) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	a.calls++
+	return &aiservice.Response{Category: "Food"}, nil
+}
+
+func newTestBudget(t *testing.T, limit float64, onExceeded func(month Month, limit float64)) *Budget {
+	t.Helper()
+	t.Setenv("MORPH_STORAGE_DIR", t.TempDir())
+	budget := New(filestore.FileStore{}, limit, onExceeded)
+	budget.now = func() time.Time { return time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC) }
+	return budget
+}
+
+func TestEstimateCost(t *testing.T) {
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{"gpt-4o", 2.50 + 10.00},
+		{"gpt-4o-2024-08-06", 2.50 + 10.00},
+		{"gpt-4o-mini", 0.15 + 0.60},
+		{"llama3.1", 0},
+		{"gpt-4o-transcribe", 6.00 + 10.00},
+	}
+
+	for _, tt := range tests {
+		got := EstimateCost(aiservice.Usage{Model: tt.model, PromptTokens: 1_000_000, CompletionTokens: 1_000_000})
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("EstimateCost(%s) = %f, want %f", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateCost_Audio(t *testing.T) {
+	if got := EstimateCost(aiservice.Usage{Model: "whisper-1", AudioSeconds: 90}); math.Abs(got-0.009) > 1e-9 {
+		t.Errorf("EstimateCost(whisper-1, 90s) = %f, want 0.009", got)
+	}
+}
+
+func TestBudget_RecordsMonthlyTotalAndWarnsOnce(t *testing.T) {
+	warnings := 0
+	budget := newTestBudget(t, 0.01, func(month Month, limit float64) {
+		warnings++
+	})
+
+	usage := aiservice.Usage{Model: "gpt-4o", PromptTokens: 2000, CompletionTokens: 100}
+	budget.Record(usage)
+	if budget.Exceeded() {
+		t.Fatal("Expected budget not to be exceeded after one call")
+	}
+
+	budget.Record(usage)
+	budget.Record(usage)
+
+	month := budget.Current()
+	if month.Month != "2026-10" || month.Calls != 3 || month.PromptTokens != 6000 || month.CompletionTokens != 300 {
+		t.Errorf("Unexpected month totals: %+v", month)
+	}
+	if !budget.Exceeded() {
+		t.Error("Expected budget to be exceeded")
+	}
+	if warnings != 1 {
+		t.Errorf("Expected 1 warning, got %d", warnings)
+	}
+}
+
+func TestBudget_NewMonthStartsFresh(t *testing.T) {
+	budget := newTestBudget(t, 0.01, nil)
+	budget.Record(aiservice.Usage{Model: "gpt-4o", PromptTokens: 1_000_000})
+	if !budget.Exceeded() {
+		t.Fatal("Expected budget to be exceeded")
+	}
+
+	budget.now = func() time.Time { return time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC) }
+	if budget.Exceeded() {
+		t.Error("Expected a new month to reset the budget")
+	}
+}
+
+func TestGuard_SkipsAIWhenExceeded(t *testing.T) {
+	budget := newTestBudget(t, 0.01, nil)
+	ai := &countingAI{}
+	guard := Guard{Service: ai, Budget: budget}
+	ctx := context.Background()
+
+	if _, err := guard.Request("Morph", "desc", "system", "user", &ctx); err != nil {
+		t.Fatalf("Expected a response within budget, got %v", err)
+	}
+
+	budget.Record(aiservice.Usage{Model: "gpt-4o", PromptTokens: 1_000_000})
+	if _, err := guard.Request("Morph", "desc", "system", "user", &ctx); !errors.Is(err, aiservice.ErrBudgetExceeded) {
+		t.Errorf("Expected ErrBudgetExceeded once the budget is exceeded, got %v", err)
+	}
+	if ai.calls != 1 {
+		t.Errorf("Expected 1 AI call, got %d", ai.calls)
+	}
+}
+
+type countingSpeech struct {
+	calls int
+}
+
+func (s *countingSpeech) Transcribe(audio []byte, mediaType string, ctx *context.Context) (string, error) {
+	s.calls++
+	return "coffee 40", nil
+}
+
+func TestSpeechGuard_SkipsTranscriptionWhenExceeded(t *testing.T) {
+	budget := newTestBudget(t, 0.01, nil)
+	speech := &countingSpeech{}
+	guard := SpeechGuard{Service: speech, Budget: budget}
+	ctx := context.Background()
+
+	if _, err := guard.Transcribe([]byte("OggS"), "audio/ogg", &ctx); err != nil {
+		t.Fatalf("Expected a transcript within budget, got %v", err)
+	}
+
+	budget.Record(aiservice.Usage{Model: "whisper-1", AudioSeconds: 120})
+	if _, err := guard.Transcribe([]byte("OggS"), "audio/ogg", &ctx); !errors.Is(err, aiservice.ErrBudgetExceeded) {
+		t.Errorf("Expected ErrBudgetExceeded once the budget is exceeded, got %v", err)
+	}
+	if speech.calls != 1 {
+		t.Errorf("Expected 1 transcription, got %d", speech.calls)
+	}
+}
+
+func TestBudget_ZeroLimitNeverExceeds(t *testing.T) {
+	budget := newTestBudget(t, 0, nil)
+	budget.Record(aiservice.Usage{Model: "gpt-4o", PromptTokens: 10_000_000})
+	if budget.Exceeded() {
+		t.Error("Expected no limit with a zero budget")
+	}
+}
@@ -1,6 +1,8 @@
 package category
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -57,8 +59,36 @@ func init() {
 	}
 }
 
+// Taxonomy is a set of categories, each with its subcategories. A category
+// without subcategories is used on its own.
+type Taxonomy map[string][]string
+
+// Default is the built-in taxonomy. It is shared, so it must not be modified.
+func Default() Taxonomy {
+	return categories
+}
+
+// GetCategories returns a copy of the taxonomy: the subcategories of every category.
+func GetCategories() map[string][]string {
+	return Default().Copy()
+}
+
+// Copy returns a copy of the taxonomy that can be modified.
+func (t Taxonomy) Copy() Taxonomy {
+	copied := make(Taxonomy, len(t))
+	for name, subcategories := range t {
+		copied[name] = append([]string(nil), subcategories...)
+	}
+	return copied
+}
+
 func GetCategoriesInJSON() string {
-	jsonData, err := json.MarshalIndent(categories, "", "  ")
+	return Default().JSON()
+}
+
+// JSON is the taxonomy as shown to the AI.
+func (t Taxonomy) JSON() string {
+	jsonData, err := json.MarshalIndent(t, "", "  ")
 	if err != nil {
 		fmt.Println("Error marshalling categories to JSON:", err)
 		return "{}"
@@ -67,7 +97,18 @@ func GetCategoriesInJSON() string {
 }
 
 func GetHintsInJSON() string {
-	jsonData, err := json.MarshalIndent(hints, "", "  ")
+	return Default().HintsJSON()
+}
+
+// HintsJSON describes the categories of the taxonomy that have a hint.
+func (t Taxonomy) HintsJSON() string {
+	described := make(map[string]string, len(t))
+	for name := range t {
+		if hint, ok := hints[name]; ok {
+			described[name] = hint
+		}
+	}
+	jsonData, err := json.MarshalIndent(described, "", "  ")
 	if err != nil {
 		fmt.Println("Error marshalling hints to JSON:", err)
 		return "{}"
@@ -75,6 +116,19 @@ func GetHintsInJSON() string {
 	return string(jsonData)
 }
 
+// Version identifies the current taxonomy. It changes whenever a category,
+// subcategory or hint changes, so anything derived from the taxonomy can
+// tell it is stale.
+func Version() string {
+	return Default().Version()
+}
+
+// Version identifies the taxonomy together with its hints.
+func (t Taxonomy) Version() string {
+	sum := sha256.Sum256([]byte(t.JSON() + t.HintsJSON()))
+	return hex.EncodeToString(sum[:8])
+}
+
 func getCodeAsString(code int32) string {
 	return strconv.Itoa(int(code))
 }
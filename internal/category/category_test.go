@@ -11,3 +11,41 @@ func TestGetCodeAsString(t *testing.T) {
 		t.Errorf("Expected 123456, got %s", result)
 	}
 }
+
+func TestVersionChangesWithTaxonomy(t *testing.T) {
+	version := Version()
+	if version != Version() {
+		t.Fatal("Expected version to be stable")
+	}
+
+	categories["Test"] = []string{"Sub"}
+	defer delete(categories, "Test")
+
+	if Version() == version {
+		t.Error("Expected version to change with the taxonomy")
+	}
+}
+
+func TestGetCategoriesReturnsACopy(t *testing.T) {
+	copied := GetCategories()
+	copied["Food"][0] = "Changed"
+	delete(copied, "Travel")
+
+	if categories["Food"][0] == "Changed" || len(categories["Travel"]) == 0 {
+		t.Error("Expected changes to the copy to leave the taxonomy alone")
+	}
+}
+
+func TestTaxonomy_HintsOnlyForItsCategories(t *testing.T) {
+	taxonomy := Taxonomy{"Food": {"Shop"}, "Pets": {"Vet"}}
+
+	if got := taxonomy.HintsJSON(); got != "{\n  \"Food\": \""+hints["Food"]+"\"\n}" {
+		t.Errorf("Unexpected hints %s", got)
+	}
+	if taxonomy.Version() == Version() {
+		t.Error("Expected a custom taxonomy to have its own version")
+	}
+	if !taxonomy.IsValid("Pets", "Vet") || taxonomy.IsValid("Transport", "Taxi") {
+		t.Error("Expected validity to follow the custom taxonomy")
+	}
+}
@@ -0,0 +1,102 @@
+package category
+
+import "log"
+
+// Mapping is a category/subcategory pair from our own taxonomy.
+type Mapping struct {
+	Category    string
+	Subcategory string
+}
+
+// mccMappings translates merchant category codes whose meaning is unambiguous
+// into our taxonomy. Codes not listed here are left to the AI, which still
+// gets the mcc package description as a hint.
+var mccMappings = map[int32]Mapping{
+	// Transport
+	3301: {"Transport", "Plane"},
+	4111: {"Transport", "Bus"},
+	4112: {"Transport", "Train"},
+	4121: {"Transport", "Taxi"},
+	4131: {"Transport", "Bus"},
+	4511: {"Transport", "Plane"},
+	// Bills
+	4814: {"Bills", "Cellurar"},
+	4899: {"Bills", "Internet"},
+	4900: {"Bills", "Utilities"},
+	// Food
+	5411: {"Food", "Shop"},
+	5441: {"Food", "Shop"},
+	5451: {"Food", "Shop"},
+	5462: {"Food", "Shop"},
+	5499: {"Food", "Shop"},
+	5812: {"Food", "Outdoors"},
+	5813: {"Food", "Outdoors"},
+	5814: {"Food", "Outdoors"},
+	5921: {"Food", "Alcohol"},
+	// Car
+	5541: {"Car", "Fuel"},
+	5542: {"Car", "Fuel"},
+	7523: {"Car", "Parking"},
+	// Things
+	5651: {"Things", "Clothes"},
+	5661: {"Things", "Shoes"},
+	5691: {"Things", "Clothes"},
+	// Health
+	5912: {"Health", "Pharmacy"},
+	8021: {"Health", "Dentist"},
+	8042: {"Health", "Vision"},
+	8043: {"Health", "Vision"},
+	8062: {"Health", "Medicine"},
+	8071: {"Health", "Medicine"},
+	// Children
+	5945: {"Children", "Toys"},
+	8351: {"Children", "Kindergarten"},
+	// Multimedia
+	5942: {"Multimedia", "Books"},
+	// Activities
+	7832: {"Activities", "Cinema"},
+	7997: {"Activities", "Sport"},
+	// Education
+	8220: {"Education", "Courses"},
+	8299: {"Education", "Courses"},
+	// Travel
+	7011: {"Travel", "Hotel"},
+	// Business
+	9311: {"Business", "Taxes"},
+}
+
+// GetMappingFromMCC returns the taxonomy entry for an MCC code, if the code
+// is in the mapping table and points to an existing category.
+func GetMappingFromMCC(code int32) (Mapping, bool) {
+	mapping, ok := mccMappings[code]
+	if !ok {
+		return Mapping{}, false
+	}
+	if !IsValid(mapping.Category, mapping.Subcategory) {
+		log.Printf("[Category] MCC %d maps to unknown category %s/%s", code, mapping.Category, mapping.Subcategory)
+		return Mapping{}, false
+	}
+	return mapping, true
+}
+
+// IsValid reports whether category (and subcategory, when set) exist in the taxonomy.
+func IsValid(category string, subcategory string) bool {
+	return Default().IsValid(category, subcategory)
+}
+
+// IsValid reports whether category (and subcategory, when set) exist in t.
+func (t Taxonomy) IsValid(category string, subcategory string) bool {
+	subcategories, ok := t[category]
+	if !ok {
+		return false
+	}
+	if subcategory == "" {
+		return true
+	}
+	for _, s := range subcategories {
+		if s == subcategory {
+			return true
+		}
+	}
+	return false
+}
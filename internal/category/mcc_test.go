@@ -0,0 +1,50 @@
+package category
+
+import (
+	"testing"
+)
+
+func TestMCCMappingsPointToTaxonomy(t *testing.T) {
+	for code, mapping := range mccMappings {
+		if !IsValid(mapping.Category, mapping.Subcategory) {
+			t.Errorf("MCC %d maps to unknown category %s/%s", code, mapping.Category, mapping.Subcategory)
+		}
+		if _, err := GetCategoryFromMCC(code); err != nil {
+			t.Errorf("MCC %d is not known to the mcc package: %v", code, err)
+		}
+	}
+}
+
+func TestGetMappingFromMCC(t *testing.T) {
+	mapping, ok := GetMappingFromMCC(4121)
+	if !ok {
+		t.Fatal("Expected mapping for MCC 4121")
+	}
+	if mapping.Category != "Transport" || mapping.Subcategory != "Taxi" {
+		t.Errorf("Expected Transport/Taxi, got %s/%s", mapping.Category, mapping.Subcategory)
+	}
+
+	if _, ok := GetMappingFromMCC(1234); ok {
+		t.Error("Expected no mapping for MCC 1234")
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		category    string
+		subcategory string
+		want        bool
+	}{
+		{"Food", "Shop", true},
+		{"Food", "", true},
+		{"Waste", "", true},
+		{"Food", "Taxi", false},
+		{"Groceries", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsValid(tt.category, tt.subcategory); got != tt.want {
+			t.Errorf("IsValid(%q, %q) = %v, want %v", tt.category, tt.subcategory, got, tt.want)
+		}
+	}
+}
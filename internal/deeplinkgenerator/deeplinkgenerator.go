@@ -4,4 +4,7 @@ import "time"
 
 type DeepLinkGenerator interface {
 	Create(category string, subcategory string, account string, amount float64, date time.Time) string
+	// CreateDraft builds the same link without saving, so the app opens the
+	// prefilled expense for review.
+	CreateDraft(category string, subcategory string, account string, amount float64, date time.Time) string
 }
@@ -0,0 +1,137 @@
+// Package eval scores the classifier against a golden dataset and compares
+// runs, so prompt and taxonomy changes can be measured instead of guessed.
+package eval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/app"
+)
+
+// Case is a line of the dataset: a sample and the classification we expect.
+type Case struct {
+	ID string `json:"id,omitempty"`
+	app.Sample
+	Category    string `json:"category"`
+	Subcategory string `json:"subcategory"`
+}
+
+// Result is the classification of a case.
+type Result struct {
+	ID                  string `json:"id"`
+	Source              string `json:"source"`
+	ExpectedCategory    string `json:"expectedCategory"`
+	ExpectedSubcategory string `json:"expectedSubcategory"`
+	Category            string `json:"category"`
+	Subcategory         string `json:"subcategory"`
+	Error               string `json:"error,omitempty"`
+}
+
+// Correct reports whether both the category and the subcategory match.
+func (result Result) Correct() bool {
+	return result.Error == "" && result.Category == result.ExpectedCategory && result.Subcategory == result.ExpectedSubcategory
+}
+
+// Expected is the expected label as "Category/Subcategory".
+func (result Result) Expected() string {
+	return label(result.ExpectedCategory, result.ExpectedSubcategory)
+}
+
+// Got is the predicted label, or the error.
+func (result Result) Got() string {
+	if result.Error != "" {
+		return "error"
+	}
+	return label(result.Category, result.Subcategory)
+}
+
+func label(category string, subcategory string) string {
+	if subcategory == "" {
+		return category
+	}
+	return category + "/" + subcategory
+}
+
+// Run is the outcome of evaluating a dataset, saved to compare with later runs.
+type Run struct {
+	Results []Result `json:"results"`
+}
+
+// ClassifyFunc classifies a sample, normally app.Classify.
+type ClassifyFunc func(ctx context.Context, sample app.Sample) (*aiservice.Response, error)
+
+// ReadDataset reads a JSONL dataset. Blank lines and lines starting with #
+// are skipped; cases without an ID are named after their line.
+func ReadDataset(reader io.Reader) ([]Case, error) {
+	var cases []Case
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var c Case
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("line-%d", line)
+		}
+		cases = append(cases, c)
+	}
+	return cases, scanner.Err()
+}
+
+// Evaluate classifies every case in order.
+func Evaluate(ctx context.Context, cases []Case, classify ClassifyFunc) Run {
+	run := Run{Results: make([]Result, 0, len(cases))}
+	for i, c := range cases {
+		result := Result{
+			ID:                  c.ID,
+			Source:              string(c.Source),
+			ExpectedCategory:    c.Category,
+			ExpectedSubcategory: c.Subcategory,
+		}
+		response, err := classify(ctx, c.Sample)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Category = response.Category
+			result.Subcategory = response.Subcategory
+		}
+		log.Printf("[Eval] %d/%d %s: expected %s, got %s", i+1, len(cases), c.ID, result.Expected(), result.Got())
+		run.Results = append(run.Results, result)
+	}
+	return run
+}
+
+// LoadRun reads a run saved with SaveRun.
+func LoadRun(path string) (Run, error) {
+	var run Run
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return run, err
+	}
+	err = json.Unmarshal(data, &run)
+	return run, err
+}
+
+// SaveRun writes run as JSON to path.
+func SaveRun(path string, run Run) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,174 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/app"
+	"github.com/morph/internal/prompt"
+)
+
+const dataset = `# comment
+{"id":"coffee","source":"cash","text":"coffee 3","category":"Food","subcategory":"Outdoors"}
+
+{"source":"mono","transaction":{"mcc":5541,"description":"WOG","amount":-100},"category":"Car","subcategory":"Fuel"}
+{"id":"push","source":"notification","app":"BBVA","message":"Mercadona 20 €","category":"Food","subcategory":"Shop"}
+`
+
+func TestReadDataset(t *testing.T) {
+	cases, err := ReadDataset(strings.NewReader(dataset))
+	if err != nil {
+		t.Fatalf("ReadDataset failed: %v", err)
+	}
+	if len(cases) != 3 {
+		t.Fatalf("expected 3 cases, got %d", len(cases))
+	}
+	if cases[0].ID != "coffee" || cases[0].Source != prompt.SourceCash || cases[0].Text != "coffee 3" {
+		t.Errorf("unexpected cash case: %+v", cases[0])
+	}
+	if cases[1].ID != "line-4" || cases[1].Transaction == nil || cases[1].Transaction.MCC != 5541 {
+		t.Errorf("unexpected mono case: %+v", cases[1])
+	}
+	if cases[2].App != "BBVA" || cases[2].Category != "Food" || cases[2].Subcategory != "Shop" {
+		t.Errorf("unexpected notification case: %+v", cases[2])
+	}
+}
+
+func TestReadDataset_ReportsTheBrokenLine(t *testing.T) {
+	_, err := ReadDataset(strings.NewReader("{\"source\":\"cash\"}\n{broken\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error for line 2, got %v", err)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	cases, _ := ReadDataset(strings.NewReader(dataset))
+	run := Evaluate(context.Background(), cases, func(ctx context.Context, sample app.Sample) (*aiservice.Response, error) {
+		switch sample.Source {
+		case prompt.SourceCash:
+			return &aiservice.Response{Category: "Food", Subcategory: "Outdoors"}, nil
+		case prompt.SourceMono:
+			return &aiservice.Response{Category: "Car", Subcategory: "Other"}, nil
+		default:
+			return nil, errors.New("timeout")
+		}
+	})
+
+	if len(run.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(run.Results))
+	}
+	if !run.Results[0].Correct() {
+		t.Errorf("expected the cash case to be correct: %+v", run.Results[0])
+	}
+	if run.Results[1].Correct() || run.Results[1].Got() != "Car/Other" {
+		t.Errorf("expected the mono case to be wrong: %+v", run.Results[1])
+	}
+	if run.Results[2].Error != "timeout" || run.Results[2].Got() != "error" {
+		t.Errorf("expected the notification case to record the error: %+v", run.Results[2])
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	run := Run{Results: []Result{
+		{ID: "a", Source: "cash", ExpectedCategory: "Food", ExpectedSubcategory: "Shop", Category: "Food", Subcategory: "Shop"},
+		{ID: "b", Source: "cash", ExpectedCategory: "Food", ExpectedSubcategory: "Shop", Category: "Food", Subcategory: "Other"},
+		{ID: "c", Source: "mono", ExpectedCategory: "Food", ExpectedSubcategory: "Shop", Category: "Food", Subcategory: "Other"},
+		{ID: "d", Source: "mono", ExpectedCategory: "Car", ExpectedSubcategory: "Fuel", Error: "timeout"},
+	}}
+
+	var out bytes.Buffer
+	WriteReport(&out, run)
+	report := out.String()
+
+	for _, want := range []string{
+		"Accuracy:  25.0% (1/4)",
+		"cash            50.0% (1/2)",
+		"mono             0.0% (0/2)",
+		"Car              0.0% (0/1)",
+		"Food            33.3% (1/3)",
+		"2 × Food/Shop → Food/Other",
+		"1 × Car/Fuel → error",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Index(report, "2 × Food/Shop") > strings.Index(report, "1 × Car/Fuel") {
+		t.Errorf("expected the most frequent confusion first:\n%s", report)
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	previous := Run{Results: []Result{
+		{ID: "fixed", ExpectedCategory: "Food", ExpectedSubcategory: "Shop", Category: "Food", Subcategory: "Other"},
+		{ID: "broken", ExpectedCategory: "Car", ExpectedSubcategory: "Fuel", Category: "Car", Subcategory: "Fuel"},
+		{ID: "changed", ExpectedCategory: "Bills", ExpectedSubcategory: "Internet", Category: "Bills", Subcategory: "Other"},
+		{ID: "same", ExpectedCategory: "Health", ExpectedSubcategory: "Pharmacy", Category: "Health", Subcategory: "Pharmacy"},
+	}}
+	current := Run{Results: []Result{
+		{ID: "fixed", ExpectedCategory: "Food", ExpectedSubcategory: "Shop", Category: "Food", Subcategory: "Shop"},
+		{ID: "broken", ExpectedCategory: "Car", ExpectedSubcategory: "Fuel", Error: "timeout"},
+		{ID: "changed", ExpectedCategory: "Bills", ExpectedSubcategory: "Internet", Category: "Bills", Subcategory: "Cellurar"},
+		{ID: "same", ExpectedCategory: "Health", ExpectedSubcategory: "Pharmacy", Category: "Health", Subcategory: "Pharmacy"},
+		{ID: "new", ExpectedCategory: "Food", ExpectedSubcategory: "Shop", Category: "Car", Subcategory: "Fuel"},
+	}}
+
+	var out bytes.Buffer
+	WriteDiff(&out, previous, current)
+	diff := out.String()
+
+	for _, want := range []string{
+		"1 fixed, 1 broken, 1 changed",
+		"fixed: Food/Other → Food/Shop",
+		"broken: Car/Fuel → error",
+		"changed: Bills/Other → Bills/Cellurar",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff is missing %q:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "same:") || strings.Contains(diff, "new:") {
+		t.Errorf("expected unchanged and new cases to be left out:\n%s", diff)
+	}
+}
+
+func TestSaveAndLoadRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	run := Run{Results: []Result{{ID: "a", Source: "cash", Category: "Food", Subcategory: "Shop", Error: "x"}}}
+	if err := SaveRun(path, run); err != nil {
+		t.Fatalf("SaveRun failed: %v", err)
+	}
+	loaded, err := LoadRun(path)
+	if err != nil {
+		t.Fatalf("LoadRun failed: %v", err)
+	}
+	if len(loaded.Results) != 1 || loaded.Results[0] != run.Results[0] {
+		t.Errorf("expected %+v, got %+v", run, loaded)
+	}
+	if _, err := LoadRun(filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+}
+
+func TestGoldenDatasetParses(t *testing.T) {
+	file, err := os.Open("../../eval/golden.jsonl")
+	if err != nil {
+		t.Fatalf("could not open golden dataset: %v", err)
+	}
+	defer file.Close()
+	cases, err := ReadDataset(file)
+	if err != nil {
+		t.Fatalf("golden dataset is invalid: %v", err)
+	}
+	for _, c := range cases {
+		if c.Category == "" || c.Subcategory == "" {
+			t.Errorf("case %s has no expected classification", c.ID)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+type tally struct {
+	correct int
+	total   int
+}
+
+func (t tally) String() string {
+	if t.total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%5.1f%% (%d/%d)", 100*float64(t.correct)/float64(t.total), t.correct, t.total)
+}
+
+func sortedKeys(m map[string]*tally) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteReport writes the overall accuracy, the accuracy per source and per
+// expected category, and the confusions, most frequent first.
+func WriteReport(w io.Writer, run Run) {
+	overall := tally{}
+	bySource := map[string]*tally{}
+	byCategory := map[string]*tally{}
+	confusions := map[[2]string]int{}
+
+	for _, result := range run.Results {
+		for _, t := range []*tally{&overall, entry(bySource, result.Source), entry(byCategory, result.ExpectedCategory)} {
+			t.total++
+			if result.Correct() {
+				t.correct++
+			}
+		}
+		if !result.Correct() {
+			confusions[[2]string{result.Expected(), result.Got()}]++
+		}
+	}
+
+	fmt.Fprintf(w, "Accuracy: %s\n", overall)
+	fmt.Fprintln(w, "\nBy source:")
+	for _, source := range sortedKeys(bySource) {
+		fmt.Fprintf(w, "  %-14s %s\n", source, bySource[source])
+	}
+	fmt.Fprintln(w, "\nBy category:")
+	for _, category := range sortedKeys(byCategory) {
+		fmt.Fprintf(w, "  %-14s %s\n", category, byCategory[category])
+	}
+
+	if len(confusions) == 0 {
+		return
+	}
+	pairs := make([][2]string, 0, len(confusions))
+	for pair := range confusions {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if confusions[pairs[i]] != confusions[pairs[j]] {
+			return confusions[pairs[i]] > confusions[pairs[j]]
+		}
+		return pairs[i][0]+pairs[i][1] < pairs[j][0]+pairs[j][1]
+	})
+	fmt.Fprintln(w, "\nConfusions (expected → got):")
+	for _, pair := range pairs {
+		fmt.Fprintf(w, "  %d × %s → %s\n", confusions[pair], pair[0], pair[1])
+	}
+}
+
+func entry(m map[string]*tally, key string) *tally {
+	if m[key] == nil {
+		m[key] = &tally{}
+	}
+	return m[key]
+}
+
+// WriteDiff lists the cases that changed since previous: fixed, broken, and
+// still wrong but with a different answer. Cases are matched by ID.
+func WriteDiff(w io.Writer, previous Run, current Run) {
+	before := map[string]Result{}
+	for _, result := range previous.Results {
+		before[result.ID] = result
+	}
+
+	var fixed, broken, changed []string
+	for _, result := range current.Results {
+		old, ok := before[result.ID]
+		if !ok {
+			continue
+		}
+		line := fmt.Sprintf("  %s: %s → %s (expected %s)", result.ID, old.Got(), result.Got(), result.Expected())
+		switch {
+		case !old.Correct() && result.Correct():
+			fixed = append(fixed, line)
+		case old.Correct() && !result.Correct():
+			broken = append(broken, line)
+		case !result.Correct() && old.Got() != result.Got():
+			changed = append(changed, line)
+		}
+	}
+
+	fmt.Fprintf(w, "\nCompared with the previous run: %d fixed, %d broken, %d changed\n", len(fixed), len(broken), len(changed))
+	for _, section := range []struct {
+		title string
+		lines []string
+	}{{"Fixed", fixed}, {"Broken", broken}, {"Changed", changed}} {
+		if len(section.lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s:\n", section.title)
+		for _, line := range section.lines {
+			fmt.Fprintln(w, line)
+		}
+	}
+}
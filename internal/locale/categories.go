@@ -0,0 +1,102 @@
+package locale
+
+// categoryNames are the display names of the built-in categories. English
+// shows the canonical names.
+var categoryNames = map[Locale]map[string]string{
+	Ukrainian: {
+		"Huge":       "Великі покупки",
+		"Bills":      "Рахунки",
+		"Devices":    "Пристрої",
+		"Gifts":      "Подарунки",
+		"Car":        "Авто",
+		"Children":   "Діти",
+		"Business":   "Бізнес",
+		"Help":       "Допомога",
+		"Transport":  "Транспорт",
+		"Activities": "Дозвілля",
+		"Food":       "Їжа",
+		"Things":     "Речі",
+		"Education":  "Освіта",
+		"Health":     "Здоров'я",
+		"House":      "Дім",
+		"Multimedia": "Мультимедіа",
+		"Travel":     "Подорожі",
+		"Waste":      "Марнотратство",
+		"Other":      "Інше",
+	},
+}
+
+// subcategoryNames are the display names of the built-in subcategories,
+// which mean the same under every category they appear in.
+var subcategoryNames = map[Locale]map[string]string{
+	Ukrainian: {
+		"Accessories":    "Аксесуари",
+		"Accountability": "Бухгалтерія",
+		"Accounts":       "Рахунки",
+		"Activities":     "Розваги",
+		"Alcohol":        "Алкоголь",
+		"Applications":   "Застосунки",
+		"Books":          "Книги",
+		"Broker":         "Брокер",
+		"Bus":            "Автобус",
+		"Car":            "Авто",
+		"Cellurar":       "Мобільний зв'язок",
+		"Cinema":         "Кіно",
+		"Clothes":        "Одяг",
+		"Courses":        "Курси",
+		"Dentist":        "Стоматолог",
+		"Design":         "Дизайн",
+		"Details":        "Дрібниці",
+		"Donation":       "Донати",
+		"Dwelling":       "Житло",
+		"Excursion":      "Екскурсії",
+		"Family":         "Родина",
+		"Fee":            "Комісії",
+		"Finances":       "Фінанси",
+		"Friends":        "Друзі",
+		"Fuel":           "Пальне",
+		"Furniture":      "Меблі",
+		"Games":          "Ігри",
+		"Garage":         "Гараж",
+		"Hospital":       "Лікарня",
+		"Hotel":          "Готель",
+		"Insurance":      "Страхування",
+		"Internet":       "Інтернет",
+		"Kindergarten":   "Садочок",
+		"Language":       "Мови",
+		"Laptop":         "Ноутбук",
+		"Lawyer":         "Юрист",
+		"Maintenance":    "Обслуговування",
+		"Medicine":       "Ліки",
+		"Mental":         "Психотерапія",
+		"Movies":         "Фільми",
+		"Music":          "Музика",
+		"Other":          "Інше",
+		"Outdoors":       "Кафе й ресторани",
+		"Parking":        "Паркування",
+		"Permission":     "Візи",
+		"Pharmacy":       "Аптека",
+		"Phone":          "Телефон",
+		"Plane":          "Літак",
+		"Rent":           "Оренда",
+		"Salary":         "Зарплата",
+		"Shoes":          "Взуття",
+		"Shop":           "Продукти",
+		"Software":       "Програми",
+		"Sport":          "Спорт",
+		"Storage":        "Сховище",
+		"Subway":         "Метро",
+		"Swimming":       "Плавання",
+		"TV Set":         "Телевізор",
+		"Taxes":          "Податки",
+		"Taxi":           "Таксі",
+		"Things":         "Речі",
+		"Toys":           "Іграшки",
+		"Train":          "Потяг",
+		"Translations":   "Переклади",
+		"Travel":         "Відрядження",
+		"Utilities":      "Комунальні",
+		"Vision":         "Зір",
+		"Vocal":          "Вокал",
+	},
+}
@@ -0,0 +1,86 @@
+// Package locale holds the text the bot shows to users in every language it
+// speaks, including display names for the built-in categories. The canonical
+// category names stay in English, as MoneyWiz knows them.
+package locale
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Locale is a language the bot replies in, as an ISO 639-1 code.
+type Locale string
+
+const (
+	English   Locale = "en"
+	Ukrainian Locale = "uk"
+)
+
+// Default is the locale used when none is configured.
+const Default = English
+
+// catalogues holds the messages of every supported locale. English has every
+// key; the others fall back to it.
+var catalogues = map[Locale]map[string]string{
+	English:   english,
+	Ukrainian: ukrainian,
+}
+
+// Supported lists the locales, English first.
+func Supported() []Locale {
+	return []Locale{English, Ukrainian}
+}
+
+// Parse reads a locale code such as "uk" or "en-US", reporting whether the
+// language is supported.
+func Parse(code string) (Locale, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	language, _, _ := strings.Cut(strings.ReplaceAll(code, "_", "-"), "-")
+	if language == "ua" {
+		language = string(Ukrainian)
+	}
+	if _, ok := catalogues[Locale(language)]; !ok {
+		return Default, false
+	}
+	return Locale(language), true
+}
+
+// Name is the locale's name in its own language.
+func (l Locale) Name() string {
+	return l.Text("language.name")
+}
+
+// Text returns the message for key in l, formatted with args like
+// fmt.Sprintf. A key missing in l falls back to English.
+func (l Locale) Text(key string, args ...any) string {
+	message, ok := catalogues[l][key]
+	if !ok {
+		message, ok = english[key]
+	}
+	if !ok {
+		log.Printf("[Locale] No message %q", key)
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Category is the display name of a category. Categories without a
+// translation, such as those of a custom taxonomy, keep their name.
+func (l Locale) Category(name string) string {
+	if display, ok := categoryNames[l][name]; ok {
+		return display
+	}
+	return name
+}
+
+// Subcategory is the display name of a subcategory.
+func (l Locale) Subcategory(name string) string {
+	if display, ok := subcategoryNames[l][name]; ok {
+		return display
+	}
+	return name
+}
@@ -0,0 +1,67 @@
+package locale
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+var verb = regexp.MustCompile(`%[-+# 0]*[0-9.]*[a-zA-Z%]`)
+
+func TestCatalogues_MatchEnglish(t *testing.T) {
+	for _, l := range Supported() {
+		for key, message := range english {
+			translated, ok := catalogues[l][key]
+			if !ok {
+				t.Errorf("%s: no message %q", l, key)
+				continue
+			}
+			if want, got := verb.FindAllString(message, -1), verb.FindAllString(translated, -1); !slices.Equal(want, got) {
+				t.Errorf("%s %q: verbs %v, want %v", l, key, got, want)
+			}
+		}
+		for key := range catalogues[l] {
+			if _, ok := english[key]; !ok {
+				t.Errorf("%s: message %q is not in English", l, key)
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := map[string]Locale{"en": English, "EN-us": English, "uk": Ukrainian, "uk_UA": Ukrainian, " ua ": Ukrainian}
+	for code, want := range cases {
+		if got, ok := Parse(code); !ok || got != want {
+			t.Errorf("Parse(%q) = %q, %v, want %q", code, got, ok, want)
+		}
+	}
+	for _, code := range []string{"", "de", "english"} {
+		if got, ok := Parse(code); ok || got != Default {
+			t.Errorf("Parse(%q) = %q, %v, want unsupported", code, got, ok)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	if got := Ukrainian.Text("command.unknown", "start"); got != "🤷 Невідома команда /start. Надішліть /help, щоб побачити список." {
+		t.Errorf("Ukrainian = %q", got)
+	}
+	if got := Locale("de").Text("undo.nothing"); got != "Nothing to undo" {
+		t.Errorf("unknown locale = %q, want the English message", got)
+	}
+	if got := English.Text("missing.key"); got != "missing.key" {
+		t.Errorf("missing key = %q", got)
+	}
+}
+
+func TestCategoryNames(t *testing.T) {
+	if got := Ukrainian.Category("Food") + " / " + Ukrainian.Subcategory("Shop"); got != "Їжа / Продукти" {
+		t.Errorf("Ukrainian = %q", got)
+	}
+	if got := English.Category("Food"); got != "Food" {
+		t.Errorf("English = %q", got)
+	}
+	if got := Ukrainian.Category("Custom"); got != "Custom" {
+		t.Errorf("untranslated = %q", got)
+	}
+}
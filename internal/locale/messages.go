@@ -0,0 +1,139 @@
+package locale
+
+// english has every message. The values are fmt formats where the message
+// takes arguments.
+var english = map[string]string{
+	"language.name": "English",
+
+	"field.category":     "Category:",
+	"field.subcategory":  "Subcategory:",
+	"field.amount":       "Amount:",
+	"field.account":      "Account:",
+	"field.merchant":     "Merchant:",
+	"field.date":         "Date:",
+	"refund":             "🔄 Refund",
+	"no_wallet":          "⚠️ No cash wallet for %s, pick the account in MoneyWiz",
+	"link.save":          "💾 Save to MoneyWiz",
+	"link.not_shortened": "⚠️ Link not shortened:",
+	"candidates":         "🤔 Not sure (%.0f%%), pick one:",
+	"not_receipt":        "🧾 This doesn't look like a receipt",
+	"not_transaction":    "🏦 This doesn't look like a bank transaction",
+
+	"error.download":      "📎 Could not download the file",
+	"error.not_image":     "🖼 Only photos of receipts can be read",
+	"error.speech_off":    "🎙 Voice messages are not set up",
+	"error.transcription": "🎙 Could not transcribe the voice message",
+	"error.timeout":       "⏱ AI took too long to respond",
+	"error.rate_limited":  "🚦 AI rate limit reached",
+	"error.refusal":       "🙅 AI refused to classify this",
+	"error.invalid_json":  "🧩 AI returned an invalid answer",
+	"error.empty":         "🫙 AI returned an empty answer",
+	"error.budget":        "💸 AI budget for this month is used up",
+	"error.vision":        "🖼 The AI model can't read images",
+	"error.no_response":   "No response from AI",
+
+	"mono.server_error":   "❌ [Mono] POST 500 error: Internal server error occurred",
+	"mono.category_error": "❌ [Mono] Error getting category: %v",
+	"mcc.report":          "⚠️ MCC codes not found since the last report:",
+	"budget.exceeded":     "💸 AI budget of $%.2f for %s is used up ($%.2f, %d calls). Until next month only MCC rules, cached classifications and the offline model are used.",
+
+	"command.unknown":    "🤷 Unknown command /%s. Send /help for the list.",
+	"command.help":       "what the bot understands",
+	"command.categories": "the categories and subcategories",
+	"command.accounts":   "the cash wallets and account names",
+	"command.undo":       "retract the last entry",
+	"command.status":     "AI provider, budget and models",
+	"command.language":   "the language of the replies",
+
+	"help.intro":        "Send an expense such as \"coffee 65\", a photo of a receipt or a voice note, and I'll reply with a MoneyWiz link.",
+	"categories.title":  "🗂 Categories",
+	"accounts.wallets":  "💼 Cash wallets",
+	"accounts.default":  "Without a currency: %s",
+	"accounts.names":    "🏷 Account names",
+	"accounts.no_names": "None, set MORPH_ACCOUNT_ALIASES to name accounts in messages",
+	"undo.nothing":      "Nothing to undo",
+	"undo.deleted":      "↩️ Retracted: %s\nIts link was deleted; if you already saved it, delete it in MoneyWiz.",
+	"undo.retracted":    "↩️ Retracted: %s\nDon't open its link; if you already saved it, delete it in MoneyWiz.",
+	"undo.bank":         "🏦 %s came from the bank, so its reply can't be retracted from the chat. If you already saved it, delete it in MoneyWiz.",
+	"status.title":      "⚙️ Status",
+	"status.ai":         "AI: %s",
+	"status.budget":     "Budget: $%.2f of $%.2f in %s, %d calls",
+	"status.used_up":    " — used up",
+	"status.usage":      "Usage: $%.2f in %s, %d calls",
+	"status.offline":    "Offline model: %d examples in %d classes",
+	"status.voice_on":   "Voice notes: on",
+	"status.voice_off":  "Voice notes: off",
+	"status.taxonomy":   "Taxonomy: %s",
+	"status.prompts":    "Prompts: %s",
+	"language.current":  "🌐 Replies are in %s. Available: %s. Send e.g. /language uk to switch.",
+	"language.set":      "🌐 Replies are now in %s.",
+	"language.unknown":  "🤷 Unknown language %q. Available: %s.",
+}
+
+var ukrainian = map[string]string{
+	"language.name": "Українська",
+
+	"field.category":     "Категорія:",
+	"field.subcategory":  "Підкатегорія:",
+	"field.amount":       "Сума:",
+	"field.account":      "Рахунок:",
+	"field.merchant":     "Продавець:",
+	"field.date":         "Дата:",
+	"refund":             "🔄 Повернення",
+	"no_wallet":          "⚠️ Немає готівкового гаманця для %s, оберіть рахунок у MoneyWiz",
+	"link.save":          "💾 Зберегти в MoneyWiz",
+	"link.not_shortened": "⚠️ Посилання не скорочено:",
+	"candidates":         "🤔 Не впевнений (%.0f%%), оберіть:",
+	"not_receipt":        "🧾 Це не схоже на чек",
+	"not_transaction":    "🏦 Це не схоже на банківську операцію",
+
+	"error.download":      "📎 Не вдалося завантажити файл",
+	"error.not_image":     "🖼 Я читаю лише фото чеків",
+	"error.speech_off":    "🎙 Голосові повідомлення не налаштовані",
+	"error.transcription": "🎙 Не вдалося розпізнати голосове повідомлення",
+	"error.timeout":       "⏱ ШІ відповідав надто довго",
+	"error.rate_limited":  "🚦 Досягнуто ліміту запитів до ШІ",
+	"error.refusal":       "🙅 ШІ відмовився це класифікувати",
+	"error.invalid_json":  "🧩 ШІ повернув некоректну відповідь",
+	"error.empty":         "🫙 ШІ повернув порожню відповідь",
+	"error.budget":        "💸 Бюджет на ШІ цього місяця вичерпано",
+	"error.vision":        "🖼 Модель ШІ не вміє читати зображення",
+	"error.no_response":   "Немає відповіді від ШІ",
+
+	"mono.server_error":   "❌ [Mono] Помилка POST 500: внутрішня помилка сервера",
+	"mono.category_error": "❌ [Mono] Не вдалося визначити категорію: %v",
+	"mcc.report":          "⚠️ MCC-коди, не знайдені з минулого звіту:",
+	"budget.exceeded":     "💸 Бюджет на ШІ $%.2f за %s вичерпано ($%.2f, викликів: %d). До наступного місяця працюють лише правила MCC, кеш класифікацій та офлайн-модель.",
+
+	"command.unknown":    "🤷 Невідома команда /%s. Надішліть /help, щоб побачити список.",
+	"command.help":       "що розуміє бот",
+	"command.categories": "категорії та підкатегорії",
+	"command.accounts":   "готівкові гаманці та назви рахунків",
+	"command.undo":       "скасувати останній запис",
+	"command.status":     "провайдер ШІ, бюджет і моделі",
+	"command.language":   "мова відповідей",
+
+	"help.intro":        "Надішліть витрату, наприклад «кава 65», фото чека або голосове повідомлення, і я відповім посиланням для MoneyWiz.",
+	"categories.title":  "🗂 Категорії",
+	"accounts.wallets":  "💼 Готівкові гаманці",
+	"accounts.default":  "Без валюти: %s",
+	"accounts.names":    "🏷 Назви рахунків",
+	"accounts.no_names": "Немає, задайте MORPH_ACCOUNT_ALIASES, щоб називати рахунки в повідомленнях",
+	"undo.nothing":      "Нічого скасовувати",
+	"undo.deleted":      "↩️ Скасовано: %s\nПосилання видалено; якщо ви вже зберегли запис, видаліть його в MoneyWiz.",
+	"undo.retracted":    "↩️ Скасовано: %s\nНе відкривайте посилання; якщо ви вже зберегли запис, видаліть його в MoneyWiz.",
+	"undo.bank":         "🏦 %s надійшов від банку, тож його відповідь не можна скасувати в чаті. Якщо ви вже зберегли запис, видаліть його в MoneyWiz.",
+	"status.title":      "⚙️ Стан",
+	"status.ai":         "ШІ: %s",
+	"status.budget":     "Бюджет: $%.2f з $%.2f за %s, викликів: %d",
+	"status.used_up":    " — вичерпано",
+	"status.usage":      "Витрачено: $%.2f за %s, викликів: %d",
+	"status.offline":    "Офлайн-модель: %d прикладів у %d класах",
+	"status.voice_on":   "Голосові повідомлення: увімкнено",
+	"status.voice_off":  "Голосові повідомлення: вимкнено",
+	"status.taxonomy":   "Таксономія: %s",
+	"status.prompts":    "Промпти: %s",
+	"language.current":  "🌐 Мова відповідей: %s. Доступні: %s. Надішліть, наприклад, /language en, щоб змінити.",
+	"language.set":      "🌐 Тепер відповідаю мовою: %s.",
+	"language.unknown":  "🤷 Невідома мова %q. Доступні: %s.",
+}
@@ -0,0 +1,159 @@
+package offlineai
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Label is what the model predicts for a text.
+type Label struct {
+	Category      string `json:"category"`
+	Subcategory   string `json:"subcategory"`
+	IsTransaction bool   `json:"isTransaction"`
+}
+
+func (label Label) key() string {
+	if label.IsTransaction {
+		return label.Category + "/" + label.Subcategory
+	}
+	return label.Category + "/" + label.Subcategory + "/-"
+}
+
+type class struct {
+	Label     Label          `json:"label"`
+	Documents int            `json:"documents"`
+	Tokens    int            `json:"tokens"`
+	Counts    map[string]int `json:"counts"`
+}
+
+// Prediction is a label with its posterior probability. Evidence counts the
+// words of the text the label was learned with; with none, only the label's
+// prior made it rank.
+type Prediction struct {
+	Label       Label
+	Probability float64
+	Evidence    int
+}
+
+// Model is a multinomial naive Bayes classifier over the words of a text and
+// the MCC code in it. It is safe for concurrent use and serializes to JSON.
+type Model struct {
+	mutex      sync.Mutex
+	Classes    map[string]*class `json:"classes"`
+	Vocabulary map[string]int    `json:"vocabulary"`
+	Documents  int               `json:"documents"`
+}
+
+func NewModel() *Model {
+	return &Model{Classes: map[string]*class{}, Vocabulary: map[string]int{}}
+}
+
+var mccPattern = regexp.MustCompile(`(?i)mcc:?\s*(\d{4})`)
+
+// tokenize lowercases text into words of two or more characters, dropping
+// numbers (amounts and dates say nothing about the category) except the MCC
+// code, which becomes a token of its own.
+func tokenize(text string) []string {
+	var tokens []string
+	for _, match := range mccPattern.FindAllStringSubmatch(text, -1) {
+		tokens = append(tokens, "mcc:"+match[1])
+	}
+	text = mccPattern.ReplaceAllString(text, " ")
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if len([]rune(word)) < 2 || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// Learn adds text as an example of label.
+func (model *Model) Learn(text string, label Label) {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return
+	}
+
+	model.mutex.Lock()
+	defer model.mutex.Unlock()
+
+	c, ok := model.Classes[label.key()]
+	if !ok {
+		c = &class{Label: label, Counts: map[string]int{}}
+		model.Classes[label.key()] = c
+	}
+	c.Documents++
+	model.Documents++
+	for _, token := range tokens {
+		if c.Counts[token] == 0 {
+			model.Vocabulary[token]++
+		}
+		c.Counts[token]++
+		c.Tokens++
+	}
+}
+
+// Predict ranks the labels for text, most likely first. It returns nothing
+// when the model is untrained or knows none of the words in text.
+func (model *Model) Predict(text string) []Prediction {
+	tokens := tokenize(text)
+
+	model.mutex.Lock()
+	defer model.mutex.Unlock()
+
+	known := false
+	for _, token := range tokens {
+		if model.Vocabulary[token] > 0 {
+			known = true
+			break
+		}
+	}
+	if !known || model.Documents == 0 {
+		return nil
+	}
+
+	vocabulary := float64(len(model.Vocabulary))
+	predictions := make([]Prediction, 0, len(model.Classes))
+	scores := make([]float64, 0, len(model.Classes))
+	best := math.Inf(-1)
+	for _, c := range model.Classes {
+		score := math.Log(float64(c.Documents) / float64(model.Documents))
+		evidence := 0
+		for _, token := range tokens {
+			score += math.Log((float64(c.Counts[token]) + 1) / (float64(c.Tokens) + vocabulary))
+			if c.Counts[token] > 0 {
+				evidence++
+			}
+		}
+		predictions = append(predictions, Prediction{Label: c.Label, Evidence: evidence})
+		scores = append(scores, score)
+		best = math.Max(best, score)
+	}
+
+	// Softmax of the log scores, shifted by the best one to stay finite.
+	total := 0.0
+	for i, score := range scores {
+		predictions[i].Probability = math.Exp(score - best)
+		total += predictions[i].Probability
+	}
+	for i := range predictions {
+		predictions[i].Probability /= total
+	}
+
+	sort.Slice(predictions, func(i, j int) bool {
+		if predictions[i].Probability != predictions[j].Probability {
+			return predictions[i].Probability > predictions[j].Probability
+		}
+		return predictions[i].Label.key() < predictions[j].Label.key()
+	})
+	return predictions
+}
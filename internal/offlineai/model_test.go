@@ -0,0 +1,47 @@
+package offlineai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("Classify this bank transaction: { mcc: 5411, description: Сільпо, amount: 120.50 }")
+	want := []string{"mcc:5411", "classify", "this", "bank", "transaction", "description", "сільпо", "amount"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestPredict_RanksLearnedLabels(t *testing.T) {
+	model := NewModel()
+	food := Label{Category: "Food", Subcategory: "Outdoors", IsTransaction: true}
+	taxi := Label{Category: "Transport", Subcategory: "Taxi", IsTransaction: true}
+	model.Learn("coffee 65", food)
+	model.Learn("coffee and croissant 120", food)
+	model.Learn("taxi home 230", taxi)
+	model.Learn("bolt taxi 180", taxi)
+
+	predictions := model.Predict("latte coffee 70")
+	if len(predictions) != 2 || predictions[0].Label != food || predictions[1].Label != taxi {
+		t.Fatalf("Unexpected predictions: %+v", predictions)
+	}
+	if predictions[0].Probability <= 0.5 || predictions[0].Probability+predictions[1].Probability < 0.999 {
+		t.Errorf("Unexpected probabilities: %+v", predictions)
+	}
+	if predictions[0].Evidence != 1 || predictions[1].Evidence != 0 {
+		t.Errorf("Unexpected evidence: %+v", predictions)
+	}
+}
+
+func TestPredict_UnknownWords(t *testing.T) {
+	model := NewModel()
+	if predictions := model.Predict("coffee"); predictions != nil {
+		t.Errorf("Expected nothing from an untrained model, got %+v", predictions)
+	}
+
+	model.Learn("coffee", Label{Category: "Food"})
+	if predictions := model.Predict("dentist 900"); predictions != nil {
+		t.Errorf("Expected nothing for unknown words, got %+v", predictions)
+	}
+}
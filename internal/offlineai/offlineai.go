@@ -0,0 +1,212 @@
+package offlineai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/internal/storage"
+)
+
+// storageKey holds the model, trained on the raw inputs of classifications.
+const storageKey = "offline_model_inputs"
+const maxAlternatives = 3
+
+// minConfidence is the probability below which the model gives no answer
+// rather than a guess.
+const minConfidence = 0.5
+
+// Service is an AIService that classifies with a naive Bayes model trained
+// on earlier classifications, without calling any remote API. Every lesson
+// is added to the model in storage, which other instances learn from too.
+type Service struct {
+	model   *Model
+	storage storage.Storage
+}
+
+// New loads the model saved in storage, or starts an empty one.
+func New(storage storage.Storage) *Service {
+	model := NewModel()
+	if storage != nil {
+		if _, err := storage.Load(storageKey, model); err != nil {
+			log.Printf("[OfflineAI] Could not load model: %v", err)
+			model = NewModel()
+		}
+	}
+	log.Printf("[OfflineAI] Loaded model with %d examples in %d classes", model.Documents, len(model.Classes))
+	return &Service{model: model, storage: storage}
+}
+
+// Learn teaches the model that text is classified as response. The lesson is
+// added to the stored model atomically, so lessons of instances learning at
+// once are all kept, and this instance picks up theirs.
+func (service *Service) Learn(text string, response aiservice.Response) {
+	label := Label{
+		Category:      response.Category,
+		Subcategory:   response.Subcategory,
+		IsTransaction: response.IsTransaction,
+	}
+	if service.storage == nil {
+		service.model.Learn(text, label)
+		return
+	}
+
+	stored := NewModel()
+	err := storage.Update(service.storage, storageKey, stored, func(found bool) {
+		if stored.Classes == nil {
+			stored.Classes = map[string]*class{}
+		}
+		if stored.Vocabulary == nil {
+			stored.Vocabulary = map[string]int{}
+		}
+		stored.Learn(text, label)
+	})
+	if err != nil {
+		log.Printf("[OfflineAI] Could not save model: %v", err)
+		service.model.Learn(text, label)
+		return
+	}
+
+	service.model.mutex.Lock()
+	defer service.model.mutex.Unlock()
+	service.model.Classes = stored.Classes
+	service.model.Vocabulary = stored.Vocabulary
+	service.model.Documents = stored.Documents
+}
+
+// Size reports how many examples the model learned and into how many classes.
+func (service *Service) Size() (examples int, classes int) {
+	service.model.mutex.Lock()
+	defer service.model.mutex.Unlock()
+	return service.model.Documents, len(service.model.Classes)
+}
+
+// Request classifies the input set on ctx with aiservice.WithInput; the
+// rendered prompts are ignored, as their template words are the same for
+// every input. Amounts are taken from the input text, as the model only
+// predicts the category.
+func (service *Service) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	text := aiservice.Input(ctx)
+	if text == "" {
+		return nil, fmt.Errorf("%w: no input for the offline model", aiservice.ErrEmptyChoices)
+	}
+	predictions := service.model.Predict(text)
+	if len(predictions) == 0 {
+		return nil, fmt.Errorf("%w: offline model knows nothing like this", aiservice.ErrEmptyChoices)
+	}
+
+	best := predictions[0]
+	if best.Evidence == 0 || best.Probability < minConfidence {
+		return nil, fmt.Errorf("%w: offline model is unsure (%s/%s %.2f)", aiservice.ErrEmptyChoices, best.Label.Category, best.Label.Subcategory, best.Probability)
+	}
+	response := &aiservice.Response{
+		Category:      best.Label.Category,
+		Subcategory:   best.Label.Subcategory,
+		IsTransaction: best.Label.IsTransaction,
+		Confidence:    best.Probability,
+		Alternatives:  []aiservice.Alternative{},
+	}
+	if response.IsTransaction {
+		response.Amount = extractAmount(text)
+	}
+	for _, prediction := range predictions[1:] {
+		if len(response.Alternatives) == maxAlternatives {
+			break
+		}
+		response.Alternatives = append(response.Alternatives, aiservice.Alternative{
+			Category:    prediction.Label.Category,
+			Subcategory: prediction.Label.Subcategory,
+		})
+	}
+
+	log.Printf("[OfflineAI] Classified as %s/%s (%.2f)", response.Category, response.Subcategory, response.Confidence)
+	return response, nil
+}
+
+var amountFieldPattern = regexp.MustCompile(`(?i)amount:\s*(-?\d+(?:[.,]\d+)?)`)
+var numberPattern = regexp.MustCompile(`\d+(?:[.,]\d{1,2})?`)
+
+// extractAmount finds the amount in a prompt: the "amount:" field of a bank
+// transaction, else the first number with decimals, else the first number.
+func extractAmount(text string) float64 {
+	raw := ""
+	if match := amountFieldPattern.FindStringSubmatch(text); match != nil {
+		raw = match[1]
+	} else {
+		numbers := numberPattern.FindAllString(text, -1)
+		for _, number := range numbers {
+			if strings.ContainsAny(number, ".,") {
+				raw = number
+				break
+			}
+		}
+		if raw == "" && len(numbers) > 0 {
+			raw = numbers[0]
+		}
+	}
+
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", "."), 64)
+	if err != nil {
+		return 0
+	}
+	return amount
+}
+
+// Teacher teaches the offline model with Service's confident answers so it
+// keeps up with them. It goes right around the provider, so answers served
+// from a cache aren't learned again.
+type Teacher struct {
+	Service aiservice.AIService
+	Offline *Service
+	// MinConfidence is the confidence from which answers are learned.
+	MinConfidence float64
+}
+
+func (teacher Teacher) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	response, err := teacher.Service.Request(name, description, systemPrompt, userPrompt, ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Inputs listing several expenses would teach every word all of their categories.
+	if text := aiservice.Input(ctx); text != "" && response.Confidence >= teacher.MinConfidence && len(response.Expenses) < 2 {
+		teacher.Offline.Learn(text, *response)
+	}
+	return response, nil
+}
+
+// RequestImage passes image requests through; the offline model reads text only.
+func (teacher Teacher) RequestImage(name string, description string, systemPrompt string, userPrompt string, image aiservice.Image, ctx *context.Context) (*aiservice.Response, error) {
+	return aiservice.RequestImage(teacher.Service, name, description, systemPrompt, userPrompt, image, ctx)
+}
+
+// Fallback answers from the offline model when Primary fails. Transient
+// errors are returned as they are when the caller retries them, as marked
+// with aiservice.WithRetry.
+type Fallback struct {
+	Primary aiservice.AIService
+	Offline *Service
+}
+
+func (fallback Fallback) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	response, err := fallback.Primary.Request(name, description, systemPrompt, userPrompt, ctx)
+	if err == nil || (aiservice.IsRetryable(err) && aiservice.WillRetry(ctx)) {
+		return response, err
+	}
+
+	offline, offlineErr := fallback.Offline.Request(name, description, systemPrompt, userPrompt, ctx)
+	if offlineErr != nil {
+		log.Printf("[OfflineAI] No offline answer either: %v", offlineErr)
+		return nil, err
+	}
+	log.Printf("[OfflineAI] Answered offline after: %v", err)
+	return offline, nil
+}
+
+// RequestImage passes image requests through; the offline model reads text only.
+func (fallback Fallback) RequestImage(name string, description string, systemPrompt string, userPrompt string, image aiservice.Image, ctx *context.Context) (*aiservice.Response, error) {
+	return aiservice.RequestImage(fallback.Primary, name, description, systemPrompt, userPrompt, image, ctx)
+}
@@ -0,0 +1,146 @@
+package offlineai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/morph/third_party/filestore"
+)
+
+type stubAI struct {
+	response *aiservice.Response
+	err      error
+}
+
+func (a stubAI) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	return a.response, a.err
+}
+
+func TestExtractAmount(t *testing.T) {
+	tests := map[string]float64{
+		"Classify this input: coffee 65":                                    65,
+		"{ mcc: 5411, description: Silpo, category: Food, amount: 120.50 }": 120.5,
+		"Message: Картка *0451. Списання 99,90 UAH":                         99.9,
+		"no amount": 0,
+	}
+	for text, want := range tests {
+		if got := extractAmount(text); got != want {
+			t.Errorf("extractAmount(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+// input returns a context carrying text as the input being classified.
+func input(text string) *context.Context {
+	ctx := aiservice.WithInput(context.Background(), text)
+	return &ctx
+}
+
+func TestService_PersistsWhatItLearns(t *testing.T) {
+	t.Setenv("MORPH_STORAGE_DIR", t.TempDir())
+	New(filestore.FileStore{}).Learn("coffee 65", aiservice.Response{Category: "Food", Subcategory: "Outdoors", IsTransaction: true})
+
+	service := New(filestore.FileStore{})
+	response, err := service.Request("Morph", "desc", "system", "Classify this input: coffee 80", input("coffee 80"))
+	if err != nil {
+		t.Fatalf("Expected a response, got %v", err)
+	}
+	if response.Category != "Food" || response.Subcategory != "Outdoors" || response.Amount != 80 || !response.IsTransaction {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+func TestService_KeepsLessonsOfOtherInstances(t *testing.T) {
+	t.Setenv("MORPH_STORAGE_DIR", t.TempDir())
+	first := New(filestore.FileStore{})
+	second := New(filestore.FileStore{})
+
+	first.Learn("coffee 65", aiservice.Response{Category: "Food", Subcategory: "Outdoors", IsTransaction: true})
+	second.Learn("taxi 230", aiservice.Response{Category: "Transport", Subcategory: "Taxi", IsTransaction: true})
+
+	if examples, _ := New(filestore.FileStore{}).Size(); examples != 2 {
+		t.Errorf("Expected both lessons stored, got %d examples", examples)
+	}
+	if response, err := second.Request("Morph", "desc", "system", "", input("coffee 80")); err != nil || response.Category != "Food" {
+		t.Errorf("Expected the second instance to know the first one's lesson, got %+v, %v", response, err)
+	}
+}
+
+func TestService_IgnoresThePromptTemplate(t *testing.T) {
+	service := New(nil)
+	service.Learn("coffee 65", aiservice.Response{Category: "Food", Subcategory: "Outdoors", IsTransaction: true})
+
+	if _, err := service.Request("Morph", "desc", "system", "Classify this input: coffee 80", nil); !errors.Is(err, aiservice.ErrEmptyChoices) {
+		t.Errorf("Expected no answer without the input, got %v", err)
+	}
+	if _, err := service.Request("Morph", "desc", "system", "Classify this input: coffee 80", input("dentist 900")); !errors.Is(err, aiservice.ErrEmptyChoices) {
+		t.Errorf("Expected no answer for unknown words, got %v", err)
+	}
+}
+
+func TestService_NoAnswerWhenUnsure(t *testing.T) {
+	service := New(nil)
+	service.Learn("coffee 65", aiservice.Response{Category: "Food", Subcategory: "Outdoors", IsTransaction: true})
+	service.Learn("coffee beans 300", aiservice.Response{Category: "Food", Subcategory: "Shop", IsTransaction: true})
+	service.Learn("taxi 230", aiservice.Response{Category: "Transport", Subcategory: "Taxi", IsTransaction: true})
+
+	if _, err := service.Request("Morph", "desc", "system", "", input("coffee 80")); !errors.Is(err, aiservice.ErrEmptyChoices) {
+		t.Errorf("Expected no answer between two equally likely labels, got %v", err)
+	}
+}
+
+func TestTeacher_LearnsConfidentAnswers(t *testing.T) {
+	offline := New(nil)
+
+	primary := stubAI{response: &aiservice.Response{Category: "Transport", Subcategory: "Taxi", Amount: 230, IsTransaction: true, Confidence: 0.95}}
+	if _, err := (Teacher{Service: primary, Offline: offline, MinConfidence: 0.8}).Request("Morph", "desc", "system", "Classify this input: taxi 230", input("taxi 230")); err != nil {
+		t.Fatalf("Expected primary response, got %v", err)
+	}
+	unsure := stubAI{response: &aiservice.Response{Category: "Food", Confidence: 0.3}}
+	Teacher{Service: unsure, Offline: offline, MinConfidence: 0.8}.Request("Morph", "desc", "system", "Classify this input: taxi 40", input("taxi 40"))
+
+	if examples, _ := offline.Size(); examples != 1 {
+		t.Errorf("Expected only the confident answer learned, got %d examples", examples)
+	}
+	if _, err := offline.Request("Morph", "desc", "system", "", input("classify this input")); !errors.Is(err, aiservice.ErrEmptyChoices) {
+		t.Errorf("Expected the template words not to be learned, got %v", err)
+	}
+}
+
+func TestFallback_AnswersWhenPrimaryFailsForGood(t *testing.T) {
+	offline := New(nil)
+	offline.Learn("taxi 230", aiservice.Response{Category: "Transport", Subcategory: "Taxi", IsTransaction: true})
+
+	failing := Fallback{Primary: stubAI{err: aiservice.ErrBudgetExceeded}, Offline: offline}
+	response, err := failing.Request("Morph", "desc", "system", "Classify this input: taxi 180", input("taxi 180"))
+	if err != nil {
+		t.Fatalf("Expected offline response, got %v", err)
+	}
+	if response.Category != "Transport" || response.Amount != 180 || response.Confidence != 1 {
+		t.Errorf("Unexpected offline response: %+v", response)
+	}
+
+	if _, err := failing.Request("Morph", "desc", "system", "Classify this input: dentist", input("dentist")); !errors.Is(err, aiservice.ErrBudgetExceeded) {
+		t.Errorf("Expected the primary error when offline has no answer, got %v", err)
+	}
+}
+
+func TestFallback_LeavesTransientErrorsToRetriesUntilTheLastAttempt(t *testing.T) {
+	offline := New(nil)
+	offline.Learn("taxi 230", aiservice.Response{Category: "Transport", Subcategory: "Taxi", IsTransaction: true})
+
+	for _, primaryErr := range []error{aiservice.ErrTimeout, aiservice.ErrRateLimited} {
+		failing := Fallback{Primary: stubAI{err: primaryErr}, Offline: offline}
+		retried := aiservice.WithRetry(*input("taxi 180"))
+		if _, err := failing.Request("Morph", "desc", "system", "Classify this input: taxi 180", &retried); !errors.Is(err, primaryErr) {
+			t.Errorf("Expected %v to be returned for a retry, got %v", primaryErr, err)
+		}
+
+		response, err := failing.Request("Morph", "desc", "system", "Classify this input: taxi 180", input("taxi 180"))
+		if err != nil || response.Category != "Transport" {
+			t.Errorf("Expected an offline answer on the last attempt after %v, got %+v, %v", primaryErr, response, err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package profile describes the people using the bot: where their
+// transactions come from, which chat they are answered in, and which
+// MoneyWiz accounts and taxonomy they are booked with.
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/morph/internal/category"
+)
+
+// Profile is one person using the bot.
+type Profile struct {
+	Name string `json:"name"`
+	// ChatID is the chat their bank transactions are sent to. Zero means the
+	// chat configured with MORPH_TELEGRAM_CHAT_ID.
+	ChatID int64 `json:"chatId,omitempty"`
+	// TelegramUsers are the Telegram user IDs whose messages are theirs.
+	TelegramUsers []int64 `json:"telegramUsers,omitempty"`
+	// NotificationSenders are the sender names their iOS Shortcut puts in
+	// forwarded notifications.
+	NotificationSenders []string `json:"notificationSenders,omitempty"`
+	Accounts            Accounts `json:"accounts"`
+	// Categories is their taxonomy. Empty means the built-in one.
+	Categories category.Taxonomy `json:"categories,omitempty"`
+	// Locale is the language of their replies, e.g. "uk". Empty means
+	// MORPH_LOCALE.
+	Locale string `json:"locale,omitempty"`
+}
+
+// Accounts are the MoneyWiz accounts of a profile.
+type Accounts struct {
+	// Mono maps Monobank account IDs to MoneyWiz accounts.
+	Mono map[string]string `json:"mono,omitempty"`
+	// Banks maps a bank that sends notifications ("bbva", "pumb", "privat")
+	// to its accounts.
+	Banks map[string]Bank `json:"banks,omitempty"`
+	// Cash maps ISO currency codes to cash wallets.
+	Cash map[string]string `json:"cash,omitempty"`
+	// Aliases maps names that may be written in a cash message to accounts.
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Members maps the Telegram user IDs of the people sharing a group chat
+	// to their own cash wallet, used for their cash expenses.
+	Members map[string]string `json:"members,omitempty"`
+}
+
+// Bank is how the notifications of one bank are booked.
+type Bank struct {
+	// Account books every notification of the bank on one account.
+	Account string `json:"account,omitempty"`
+	// Cards maps the masked card or account token in a notification, such as
+	// "*0451", to an account.
+	Cards map[string]string `json:"cards,omitempty"`
+}
+
+// Taxonomy is the profile's taxonomy.
+func (p *Profile) Taxonomy() category.Taxonomy {
+	if len(p.Categories) == 0 {
+		return category.Default()
+	}
+	return p.Categories
+}
+
+// Card returns the account whose masked token appears in the notification
+// message from bank. The "*" in tokens avoids collisions with amounts,
+// balances or dates.
+func (p *Profile) Card(bank string, message string) (string, bool) {
+	for token, account := range p.Accounts.Banks[bank].Cards {
+		if strings.Contains(message, token) {
+			return account, true
+		}
+	}
+	return "", false
+}
+
+// Profiles are all the profiles. The first one is the owner of anything
+// that can't be attributed, and receives the bot's own reports.
+type Profiles []Profile
+
+// Load reads profiles from a JSON file holding an array of profiles.
+func Load(path string) (Profiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var profiles Profiles
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", path, err)
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("%s has no profiles", path)
+	}
+	names := map[string]bool{}
+	for _, p := range profiles {
+		if p.Name == "" || names[p.Name] {
+			return nil, fmt.Errorf("%s: every profile needs a unique name, got %q", path, p.Name)
+		}
+		names[p.Name] = true
+	}
+	return profiles, nil
+}
+
+// find returns the first profile matching match.
+func (ps Profiles) find(match func(p *Profile) bool) (*Profile, bool) {
+	for i := range ps {
+		if match(&ps[i]) {
+			return &ps[i], true
+		}
+	}
+	return nil, false
+}
+
+// Named returns the profile called name.
+func (ps Profiles) Named(name string) (*Profile, bool) {
+	return ps.find(func(p *Profile) bool { return p.Name == name })
+}
+
+// ForTelegramUser returns the profile of a Telegram user, given as the
+// decimal user ID.
+func (ps Profiles) ForTelegramUser(userID string) (*Profile, bool) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	return ps.find(func(p *Profile) bool {
+		for _, user := range p.TelegramUsers {
+			if user == id {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ForChat returns the profile whose transactions go to chatID.
+func (ps Profiles) ForChat(chatID int64) (*Profile, bool) {
+	return ps.find(func(p *Profile) bool { return p.ChatID != 0 && p.ChatID == chatID })
+}
+
+// ForMonoAccount returns the owner of a Monobank account.
+func (ps Profiles) ForMonoAccount(accountID string) (*Profile, bool) {
+	return ps.find(func(p *Profile) bool {
+		_, ok := p.Accounts.Mono[accountID]
+		return ok
+	})
+}
+
+// ForNotificationSender returns the profile whose Shortcut sends as sender,
+// ignoring case.
+func (ps Profiles) ForNotificationSender(sender string) (*Profile, bool) {
+	if sender == "" {
+		return nil, false
+	}
+	return ps.find(func(p *Profile) bool {
+		for _, s := range p.NotificationSenders {
+			if strings.EqualFold(s, sender) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ForCard returns the owner of the card or account named in a notification
+// message from bank.
+func (ps Profiles) ForCard(bank string, message string) (*Profile, bool) {
+	return ps.find(func(p *Profile) bool {
+		_, ok := p.Card(bank, message)
+		return ok
+	})
+}
@@ -0,0 +1,110 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/morph/internal/category"
+)
+
+const profilesJSON = `[
+	{"name": "max", "telegramUsers": [111], "accounts": {"mono": {"acc-max": "MonobankUAH"}}},
+	{
+		"name": "anna",
+		"chatId": 222,
+		"telegramUsers": [333],
+		"notificationSenders": ["Anna's iPhone"],
+		"accounts": {
+			"mono": {"acc-anna": "AnnaMono"},
+			"banks": {"pumb": {"cards": {"*1234": "AnnaPumb"}}, "bbva": {"account": "AnnaBBVA"}}
+		},
+		"categories": {"Food": ["Shop"], "Other": []}
+	}
+]`
+
+func writeProfiles(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	profiles, err := Load(writeProfiles(t, profilesJSON))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(profiles) != 2 || profiles[1].Name != "anna" || profiles[1].ChatID != 222 {
+		t.Fatalf("profiles = %+v", profiles)
+	}
+
+	for name, data := range map[string]string{
+		"empty":          `[]`,
+		"unnamed":        `[{"chatId": 1}]`,
+		"duplicate name": `[{"name": "max"}, {"name": "max"}]`,
+		"not JSON":       `{`,
+	} {
+		if _, err := Load(writeProfiles(t, data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("missing file: expected an error")
+	}
+}
+
+func TestProfiles_Lookups(t *testing.T) {
+	profiles, err := Load(writeProfiles(t, profilesJSON))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		found func() (*Profile, bool)
+		want  string
+	}{
+		{"named", func() (*Profile, bool) { return profiles.Named("anna") }, "anna"},
+		{"telegram user", func() (*Profile, bool) { return profiles.ForTelegramUser("333") }, "anna"},
+		{"chat", func() (*Profile, bool) { return profiles.ForChat(222) }, "anna"},
+		{"mono account", func() (*Profile, bool) { return profiles.ForMonoAccount("acc-max") }, "max"},
+		{"sender ignores case", func() (*Profile, bool) { return profiles.ForNotificationSender("anna's iphone") }, "anna"},
+		{"card", func() (*Profile, bool) { return profiles.ForCard("pumb", "Картка *1234 Сільпо") }, "anna"},
+		{"unknown user", func() (*Profile, bool) { return profiles.ForTelegramUser("999") }, ""},
+		{"invalid user", func() (*Profile, bool) { return profiles.ForTelegramUser("") }, ""},
+		{"chat of a profile without one", func() (*Profile, bool) { return profiles.ForChat(0) }, ""},
+		{"empty sender", func() (*Profile, bool) { return profiles.ForNotificationSender("") }, ""},
+		{"card of another bank", func() (*Profile, bool) { return profiles.ForCard("privat", "*1234") }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := tt.found()
+			if tt.want == "" {
+				if ok {
+					t.Errorf("found %s, want none", p.Name)
+				}
+				return
+			}
+			if !ok || p.Name != tt.want {
+				t.Errorf("found %v, want %s", p, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfile_Taxonomy(t *testing.T) {
+	profiles, err := Load(writeProfiles(t, profilesJSON))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if profiles[0].Taxonomy().Version() != category.Version() {
+		t.Error("expected a profile without categories to use the built-in taxonomy")
+	}
+	anna := profiles[1].Taxonomy()
+	if !anna.IsValid("Food", "Shop") || anna.IsValid("Food", "Outdoors") || anna.IsValid("Transport", "") {
+		t.Errorf("taxonomy = %v", anna)
+	}
+}
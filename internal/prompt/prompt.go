@@ -0,0 +1,125 @@
+package prompt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	"github.com/morph/internal/aiservice"
+)
+
+// Source is where the classified input comes from. Each source has its own template.
+type Source string
+
+const (
+	SourceCash         Source = "cash"
+	SourceMono         Source = "mono"
+	SourceNotification Source = "notification"
+	SourceReceipt      Source = "receipt"
+)
+
+// Example is a few-shot input with the classification we expect for it.
+type Example struct {
+	Input  string
+	Output aiservice.Response
+}
+
+// Input is everything a template can render.
+type Input struct {
+	Source   Source
+	Taxonomy string
+	Hints    string
+	// Accounts lists, as JSON, the account names the user may mention.
+	Accounts string
+	// Today is the day the input was sent, e.g. "Friday, 2025-09-19", which
+	// relative dates in it are resolved against. Only the day is given so the
+	// rendered prompt, and the cache key, stay the same all day.
+	Today string
+	// Examples replace the template's default few-shot examples when set.
+	Examples []Example
+	// Text is the input to classify, already formatted for the source.
+	Text string
+}
+
+// Prompt is a rendered template, ready to be sent to the AI.
+type Prompt struct {
+	Name        string
+	Version     int
+	Description string
+	System      string
+	User        string
+}
+
+// ID identifies the template the prompt was rendered from, e.g. "cash@1".
+func (p Prompt) ID() string {
+	return fmt.Sprintf("%s@%d", p.Name, p.Version)
+}
+
+// Template is a named, versioned pair of system and user prompt templates.
+// Bump Version whenever the wording changes so classifications logged with
+// the old and new prompt can be told apart.
+type Template struct {
+	Name        string
+	Version     int
+	Description string
+	Examples    []Example
+	system      *template.Template
+	user        *template.Template
+}
+
+var funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+func newTemplate(name string, version int, description string, system string, user string, examples []Example) *Template {
+	return &Template{
+		Name:        name,
+		Version:     version,
+		Description: description,
+		Examples:    examples,
+		system:      template.Must(template.Must(template.New(name).Funcs(funcs).Parse(shared)).Parse(system)),
+		user:        template.Must(template.New(name + "_user").Parse(user)),
+	}
+}
+
+// Get returns the current template for a source.
+func Get(source Source) (*Template, bool) {
+	t, ok := templates[source]
+	return t, ok
+}
+
+// Render renders the current template for input.Source.
+func Render(input Input) (Prompt, error) {
+	t, ok := Get(input.Source)
+	if !ok {
+		return Prompt{}, fmt.Errorf("no prompt template for source %q", input.Source)
+	}
+	return t.Render(input)
+}
+
+func (t *Template) Render(input Input) (Prompt, error) {
+	if len(input.Examples) == 0 {
+		input.Examples = t.Examples
+	}
+
+	var system bytes.Buffer
+	if err := t.system.Execute(&system, input); err != nil {
+		return Prompt{}, fmt.Errorf("failed to render %s system prompt: %w", t.Name, err)
+	}
+	var user bytes.Buffer
+	if err := t.user.Execute(&user, input); err != nil {
+		return Prompt{}, fmt.Errorf("failed to render %s user prompt: %w", t.Name, err)
+	}
+
+	return Prompt{
+		Name:        t.Name,
+		Version:     t.Version,
+		Description: t.Description,
+		System:      system.String(),
+		User:        user.String(),
+	}, nil
+}
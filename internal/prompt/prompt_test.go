@@ -0,0 +1,88 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+)
+
+func TestRender_AllSourcesShareRules(t *testing.T) {
+	for _, source := range []Source{SourceCash, SourceMono, SourceNotification} {
+		t.Run(string(source), func(t *testing.T) {
+			p, err := Render(Input{Source: source, Taxonomy: `{"Food":["Shop"]}`, Hints: `{"Food":"groceries"}`, Text: "coffee 12"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if p.Name != string(source) || p.Version < 1 || p.Description == "" {
+				t.Errorf("Unexpected prompt metadata: %s %q", p.ID(), p.Description)
+			}
+			for _, want := range []string{`{"Food":["Shop"]}`, `{"Food":"groceries"}`, "isTransaction", "confidence", "alternatives", "expenses", "Only output the JSON object."} {
+				if !strings.Contains(p.System, want) {
+					t.Errorf("System prompt missing %q: %s", want, p.System)
+				}
+			}
+			if !strings.Contains(p.User, "coffee 12") {
+				t.Errorf("User prompt missing input: %s", p.User)
+			}
+		})
+	}
+}
+
+func TestRender_ExamplesOverrideDefaults(t *testing.T) {
+	p, err := Render(Input{
+		Source: SourceCash,
+		Examples: []Example{
+			{Input: "taxi 230", Output: aiservice.Response{Category: "Transport", Subcategory: "Taxi", Amount: 230, IsTransaction: true}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(p.System, "Input: taxi 230\nOutput: {\"category\":\"Transport\"") {
+		t.Errorf("System prompt missing custom example: %s", p.System)
+	}
+	if strings.Contains(p.System, "coffee 65") {
+		t.Errorf("System prompt should not contain default example: %s", p.System)
+	}
+}
+
+func TestRender_UnknownSource(t *testing.T) {
+	if _, err := Render(Input{Source: "fax"}); err == nil {
+		t.Error("Expected error for unknown source")
+	}
+}
+
+func TestPromptID(t *testing.T) {
+	if id := (Prompt{Name: "cash", Version: 3}).ID(); id != "cash@3" {
+		t.Errorf("Expected cash@3, got %s", id)
+	}
+}
+
+func TestRender_AccountsOnlyWhenConfigured(t *testing.T) {
+	p, err := Render(Input{Source: SourceCash, Accounts: `["pumb"]`})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(p.System, `matching name from this list: ["pumb"]`) {
+		t.Errorf("System prompt missing accounts: %s", p.System)
+	}
+
+	p, err = Render(Input{Source: SourceCash})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(p.System, "matching name from this list") {
+		t.Errorf("System prompt should not list accounts: %s", p.System)
+	}
+}
+
+func TestRender_TodayAnchorsRelativeDates(t *testing.T) {
+	p, err := Render(Input{Source: SourceCash, Today: "Friday, 2025-09-19"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(p.System, "The input was sent on Friday, 2025-09-19.") {
+		t.Errorf("System prompt missing today: %s", p.System)
+	}
+}
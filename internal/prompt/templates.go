@@ -0,0 +1,102 @@
+package prompt
+
+import "github.com/morph/internal/aiservice"
+
+// shared holds the blocks every system prompt is built from, so the
+// classification rules can't drift apart between sources.
+const shared = `
+{{- define "rules" -}}
+You MUST ONLY use the categories and subcategories provided below—do not invent new ones. If the input does not match any, use 'Other' for category and an empty string for subcategory. Also rate your confidence in the chosen category and subcategory as a number from 0 to 1, and list up to three other likely category/subcategory pairs as alternatives, most likely first (an empty list when you are sure).
+{{- end -}}
+
+{{- define "output" -}}
+Output a single-line JSON object with only these fields: category, subcategory, amount, currency, account, merchant, date, isTransaction, confidence, alternatives, expenses. Example of the output: {"category": "Children", "subcategory": "Vocal", "amount": 400.0, "currency": "UAH", "account": "", "merchant": "", "date": "", "isTransaction": true, "confidence": 0.9, "alternatives": [{"category": "Education", "subcategory": "Courses"}], "expenses": []}.
+{{- end -}}
+
+{{- define "currency" -}}
+Set currency to the ISO 4217 code of the currency mentioned in the input (e.g. 'грн' or '₴' is UAH, '$' is USD, '€' is EUR), or an empty string when none is mentioned.
+{{- end -}}
+
+{{- define "accounts" -}}
+{{- if .Accounts -}}
+If the input names the account or card it was paid from, set account to the matching name from this list: {{.Accounts}}; otherwise set account to an empty string.
+{{- else -}}
+Set account to an empty string.
+{{- end -}}
+{{- end -}}
+
+{{- define "date" -}}
+{{- if .Today -}}
+The input was sent on {{.Today}}. If it says when the expense happened (e.g. 'yesterday', 'on Friday', '15.09', 'вчора'), set date to that day as YYYY-MM-DD, or YYYY-MM-DD HH:MM when a time is given too, never later than the day the input was sent; otherwise set date to an empty string.
+{{- else -}}
+Set date to an empty string.
+{{- end -}}
+{{- end -}}
+
+{{- define "merchant" -}}
+Set merchant to the shop or business the input names, or an empty string when it names none.
+{{- end -}}
+
+{{- define "taxonomy" -}}
+Categories and subcategories: {{.Taxonomy}} Hints: {{.Hints}}
+{{- end -}}
+
+{{- define "examples" -}}
+{{- if .Examples}} Examples:{{range .Examples}}
+Input: {{.Input}}
+Output: {{json .Output}}{{end}}
+{{- end -}}
+{{- end -}}
+
+{{- define "closing" -}}
+IMPORTANT: Do not add any explanation or extra text. Only output the JSON object.
+{{- end -}}
+`
+
+var templates = map[Source]*Template{
+	SourceCash: newTemplate("cash", 5,
+		"Translates free input into: Category, Subcategory, Amount",
+		`You are a data analyst. Your task is to classify the input into a category, subcategory, and amount. {{template "rules" .}} The input is always an expense, so set isTransaction to true. The input may list several expenses separated by commas or new lines: then classify each of them separately into expenses (category, subcategory, amount, currency, account, date, confidence, alternatives) and fill the top-level fields from the first one; leave expenses empty for a single expense. {{template "currency" .}} {{template "accounts" .}} {{template "merchant" .}} {{template "date" .}} {{template "output" .}} {{template "taxonomy" .}}{{template "examples" .}} {{template "closing" .}}`,
+		`Classify this input: {{.Text}}`,
+		[]Example{
+			{
+				Input:  "coffee 65",
+				Output: aiservice.Response{Category: "Food", Subcategory: "Outdoors", Amount: 65, IsTransaction: true, Confidence: 0.9, Alternatives: []aiservice.Alternative{{Category: "Food", Subcategory: "Shop"}}, Expenses: []aiservice.Expense{}},
+			},
+			{
+				Input: "bread 40, taxi 230 грн",
+				Output: aiservice.Response{
+					Category: "Food", Subcategory: "Shop", Amount: 40, Currency: "UAH", IsTransaction: true, Confidence: 0.95, Alternatives: []aiservice.Alternative{},
+					Expenses: []aiservice.Expense{
+						{Category: "Food", Subcategory: "Shop", Amount: 40, Currency: "UAH", Confidence: 0.95, Alternatives: []aiservice.Alternative{}},
+						{Category: "Transport", Subcategory: "Taxi", Amount: 230, Currency: "UAH", Confidence: 0.95, Alternatives: []aiservice.Alternative{}},
+					},
+				},
+			},
+		},
+	),
+	SourceMono: newTemplate("mono", 5,
+		"Translates Monobank transaction into: Category, Subcategory, Amount",
+		`You are a data analyst. Your task is to classify the bank transaction into a category, subcategory, and amount. The transaction comes with its MCC code and, when known, the MCC category; use them together with the merchant description. {{template "rules" .}} The input is always a transaction, so set isTransaction to true. The account and currency are known from the bank, and so is the time, so set currency, account and date to empty strings. {{template "merchant" .}} {{template "output" .}} {{template "taxonomy" .}}{{template "examples" .}} {{template "closing" .}}`,
+		`Classify this bank transaction: {{.Text}}`,
+		nil,
+	),
+	SourceNotification: newTemplate("notification", 5,
+		"Translates a bank push notification into: Category, Subcategory, Amount",
+		`You are a data analyst. Your task is to analyze a bank push notification and classify it into a category, subcategory, and amount. First decide whether the notification represents an actual financial transaction (a debit or credit on an account): set isTransaction to false for anything that is not a transaction, such as promotional or marketing messages, security or login alerts, or general informational messages, and set it to true only for real transactions. {{template "rules" .}} Extract the transaction amount from the notification text as a number (use 0 when there is no transaction). {{template "currency" .}} {{template "merchant" .}} Set account and date to empty strings. {{template "output" .}} {{template "taxonomy" .}}{{template "examples" .}} {{template "closing" .}}`,
+		`Classify this bank push notification.
+{{.Text}}`,
+		[]Example{
+			{
+				Input:  "App: Privat24\nTitle: Privat24\nMessage: 🎉 Отримайте 5% кешбек цими вихідними!",
+				Output: aiservice.Response{Category: "Other", Subcategory: "", Amount: 0, IsTransaction: false, Confidence: 1, Alternatives: []aiservice.Alternative{}, Expenses: []aiservice.Expense{}},
+			},
+		},
+	),
+	SourceReceipt: newTemplate("receipt", 1,
+		"Translates a photo of a receipt into: Category, Subcategory, Amount",
+		`You are a data analyst. Your task is to read the attached photo of a purchase receipt and classify the purchase into a category, subcategory, and amount. {{template "rules" .}} Set amount to the total paid, not to a single line item, and set isTransaction to true. If the receipt clearly mixes purchases of different categories, you may also list them separately in expenses (category, subcategory, amount, currency, account, date, confidence, alternatives) with the top-level fields filled from the largest one; otherwise leave expenses empty. If the image is not a receipt, set isTransaction to false and amount to 0. {{template "currency" .}} {{template "accounts" .}} Set merchant to the shop name printed on the receipt. {{template "date" .}} Prefer the date printed on the receipt. {{template "output" .}} {{template "taxonomy" .}}{{template "examples" .}} {{template "closing" .}}`,
+		`Classify this receipt.{{if .Text}} Note from the user: {{.Text}}{{end}}`,
+		nil,
+	),
+}
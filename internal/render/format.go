@@ -0,0 +1,99 @@
+// Package render turns the bot's replies into Telegram messages, as plain
+// text or with Telegram HTML or MarkdownV2 formatting. Values such as
+// merchant names are always escaped for the format.
+package render
+
+import (
+	"html"
+	"strings"
+)
+
+// Format is how messages are marked up.
+type Format string
+
+const (
+	Text       Format = "text"
+	HTML       Format = "html"
+	MarkdownV2 Format = "markdownv2"
+)
+
+// ParseFormat reads a format name, case-insensitively. Anything unknown is Text.
+func ParseFormat(name string) Format {
+	switch format := Format(strings.ToLower(strings.TrimSpace(name))); format {
+	case HTML, MarkdownV2:
+		return format
+	default:
+		return Text
+	}
+}
+
+// ParseMode is the Telegram parse_mode for the format, empty for plain text.
+func (format Format) ParseMode() string {
+	switch format {
+	case HTML:
+		return "HTML"
+	case MarkdownV2:
+		return "MarkdownV2"
+	default:
+		return ""
+	}
+}
+
+// markdownV2Special are the characters MarkdownV2 requires escaping outside entities.
+var markdownV2Special = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// markdownV2Code escapes the inside of code entities and link URLs.
+var markdownV2Code = strings.NewReplacer(`\`, `\\`, "`", "\\`", ")", `\)`)
+
+// Escape makes text safe to show as-is in the format.
+func (format Format) Escape(text string) string {
+	switch format {
+	case HTML:
+		return html.EscapeString(text)
+	case MarkdownV2:
+		return markdownV2Special.Replace(text)
+	default:
+		return text
+	}
+}
+
+// Bold shows text in bold.
+func (format Format) Bold(text string) string {
+	switch format {
+	case HTML:
+		return "<b>" + format.Escape(text) + "</b>"
+	case MarkdownV2:
+		return "*" + format.Escape(text) + "*"
+	default:
+		return text
+	}
+}
+
+// Code shows text in monospace, e.g. a URL Telegram won't make clickable.
+func (format Format) Code(text string) string {
+	switch format {
+	case HTML:
+		return "<code>" + format.Escape(text) + "</code>"
+	case MarkdownV2:
+		return "`" + markdownV2Code.Replace(text) + "`"
+	default:
+		return text
+	}
+}
+
+// Link shows label linking to url. Plain text can't hide a URL behind a
+// label, so it shows the URL alone.
+func (format Format) Link(label string, url string) string {
+	switch format {
+	case HTML:
+		return `<a href="` + html.EscapeString(url) + `">` + format.Escape(label) + "</a>"
+	case MarkdownV2:
+		return "[" + format.Escape(label) + "](" + markdownV2Code.Replace(url) + ")"
+	default:
+		return url
+	}
+}
@@ -0,0 +1,179 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"text/template"
+
+	"github.com/morph/internal/locale"
+)
+
+// Link is a MoneyWiz link. Shortened is false when the shortener failed and
+// URL is the raw deep link, which Telegram won't make clickable. Category and
+// Subcategory label a candidate among several.
+type Link struct {
+	Category    string
+	Subcategory string
+	URL         string
+	Shortened   bool
+}
+
+// Entry is a classified expense or income. Links holds one link, or one
+// draft link per candidate when the classification is uncertain.
+type Entry struct {
+	Category    string
+	Subcategory string
+	Amount      float64
+	// Account, Merchant and Date are shown when set.
+	Account  string
+	Merchant string
+	Date     string
+	Refund   bool
+	// NoWallet is the currency named in the input when no cash wallet
+	// holds it, so the user picks the account.
+	NoWallet   string
+	Confidence float64
+	Links      []Link
+}
+
+// Cash is the reply to a cash message, receipt or voice note. Member names
+// the sender in a group chat.
+type Cash struct {
+	Member     string
+	Transcript string
+	Entries    []Entry
+}
+
+// Mono is the message for a Monobank transaction.
+type Mono struct {
+	Entry
+}
+
+// Notification is the message for a bank push notification, or for a bank
+// text a Member sent in a group chat.
+type Notification struct {
+	App    string
+	Member string
+	Entry
+}
+
+// Error is a reply explaining why an input was not classified.
+type Error struct {
+	Transcript string
+	Message    string
+}
+
+// Digest is a periodic summary, such as the unknown MCC report.
+type Digest struct {
+	Title string
+	Lines []string
+}
+
+// Event names, one template each.
+const (
+	EventCash         = "cash"
+	EventMono         = "mono"
+	EventNotification = "notification"
+	EventError        = "error"
+	EventDigest       = "digest"
+)
+
+// Literal text in the templates is limited to characters that need no
+// escaping in any format; everything else goes through esc. Words come from
+// the locale through t, and category names through category and subcategory.
+const templates = `
+{{define "member"}}{{if .Member}}👤 {{bold .Member}}
+{{end}}{{end}}
+
+{{define "transcript"}}{{if .Transcript}}🎙 "{{esc .Transcript}}"
+
+{{end}}{{end}}
+
+{{define "link"}}{{if not .Shortened}}
+{{esc (t "link.not_shortened")}}
+{{code .URL}}{{else if rich}}
+{{link (t "link.save") .URL}}{{else}}
+{{esc .URL}}{{end}}{{end}}
+
+{{define "links"}}{{if gt (len .Links) 1}}
+{{esc (t "candidates" (percent .Confidence))}}{{range $i, $link := .Links}}
+{{esc (printf "%d. " (inc $i))}}{{if and rich $link.Shortened}}{{link (label $link) $link.URL}}{{else}}{{esc (label $link)}}{{template "link" $link}}{{end}}{{end}}{{else}}{{range .Links}}{{template "link" .}}{{end}}{{end}}{{end}}
+
+{{define "entry"}}{{bold (t "field.category")}} {{esc (category .Category)}}
+{{bold (t "field.subcategory")}} {{esc (subcategory .Subcategory)}}
+{{bold (t "field.amount")}} {{amount .Amount}}{{if .Account}}
+{{bold (t "field.account")}} {{esc .Account}}{{end}}{{if .NoWallet}}
+{{esc (t "no_wallet" .NoWallet)}}{{end}}{{if .Merchant}}
+{{bold (t "field.merchant")}} {{esc .Merchant}}{{end}}{{if .Date}}
+{{bold (t "field.date")}} {{esc .Date}}{{end}}{{if .Refund}}
+{{esc (t "refund")}}{{end}}{{template "links" .}}{{end}}
+
+{{define "cash"}}{{template "member" .}}{{template "transcript" .}}{{range $i, $entry := .Entries}}{{if $i}}
+
+{{end}}{{template "entry" $entry}}{{end}}{{end}}
+
+{{define "mono"}}{{template "entry" .Entry}}{{end}}
+
+{{define "notification"}}📲 {{bold .App}}
+{{template "member" .}}{{template "entry" .Entry}}{{end}}
+
+{{define "error"}}{{template "transcript" .}}{{esc .Message}}{{end}}
+
+{{define "digest"}}{{bold .Title}}{{range .Lines}}
+{{esc .}}{{end}}{{end}}
+`
+
+var parsed = map[Format]*template.Template{}
+
+func init() {
+	for _, format := range []Format{Text, HTML, MarkdownV2} {
+		format := format
+		parsed[format] = template.Must(template.New("messages").Funcs(template.FuncMap{
+			"esc":     format.Escape,
+			"bold":    format.Bold,
+			"code":    format.Code,
+			"link":    format.Link,
+			"rich":    func() bool { return format != Text },
+			"amount":  func(amount float64) string { return format.Escape(fmt.Sprintf("%.2f", amount)) },
+			"percent": func(confidence float64) float64 { return confidence * 100 },
+			"inc":     func(i int) int { return i + 1 },
+			// Replaced per locale in Render.
+			"t":           locale.Default.Text,
+			"category":    locale.Default.Category,
+			"subcategory": locale.Default.Subcategory,
+			"label":       func(link Link) string { return "" },
+		}).Parse(templates))
+	}
+}
+
+// Render renders the template for event with data in format, in the words
+// of loc.
+func Render(format Format, loc locale.Locale, event string, data any) (string, error) {
+	t, ok := parsed[format]
+	if !ok {
+		t = parsed[Text]
+	}
+	t, err := t.Clone()
+	if err != nil {
+		return "", err
+	}
+	t.Funcs(template.FuncMap{
+		"t":           loc.Text,
+		"category":    loc.Category,
+		"subcategory": loc.Subcategory,
+		"label": func(link Link) string {
+			label := loc.Category(link.Category)
+			if link.Subcategory != "" {
+				label += " / " + loc.Subcategory(link.Subcategory)
+			}
+			return label
+		},
+	})
+	var text bytes.Buffer
+	if err := t.ExecuteTemplate(&text, event, data); err != nil {
+		log.Printf("[Render] Could not render %s message: %v", event, err)
+		return "", err
+	}
+	return text.String(), nil
+}
@@ -0,0 +1,153 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/morph/internal/locale"
+)
+
+func TestParseFormat(t *testing.T) {
+	cases := map[string]Format{"": Text, "text": Text, "HTML": HTML, " MarkdownV2 ": MarkdownV2, "markdown": Text}
+	for name, want := range cases {
+		if got := ParseFormat(name); got != want {
+			t.Errorf("ParseFormat(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if HTML.ParseMode() != "HTML" || MarkdownV2.ParseMode() != "MarkdownV2" || Text.ParseMode() != "" {
+		t.Error("unexpected parse modes")
+	}
+}
+
+func TestEscape(t *testing.T) {
+	merchant := `Tom & Jerry's <Café>_*[1].`
+	if got := HTML.Escape(merchant); got != `Tom &amp; Jerry&#39;s &lt;Café&gt;_*[1].` {
+		t.Errorf("HTML = %s", got)
+	}
+	if got := MarkdownV2.Escape(merchant); got != `Tom & Jerry's <Café\>\_\*\[1\]\.` {
+		t.Errorf("MarkdownV2 = %s", got)
+	}
+	if got := Text.Escape(merchant); got != merchant {
+		t.Errorf("Text = %s", got)
+	}
+}
+
+var entry = Entry{
+	Category:    "Food",
+	Subcategory: "Shop",
+	Amount:      12.5,
+	Merchant:    "M&M_s",
+	Links:       []Link{{URL: "https://short.example/a", Shortened: true}},
+}
+
+func TestRender_Cash(t *testing.T) {
+	data := Cash{Transcript: "coffee <12.50>", Entries: []Entry{entry, {
+		Category:    "Transport",
+		Subcategory: "Taxi",
+		Amount:      230,
+		Account:     "CashUAH",
+		Links:       []Link{{URL: "moneywiz://expense?amount=230"}},
+	}}}
+	cases := map[Format]string{
+		Text: "🎙 \"coffee <12.50>\"\n\n" +
+			"Category: Food\nSubcategory: Shop\nAmount: 12.50\nMerchant: M&M_s\nhttps://short.example/a\n\n" +
+			"Category: Transport\nSubcategory: Taxi\nAmount: 230.00\nAccount: CashUAH\n⚠️ Link not shortened:\nmoneywiz://expense?amount=230",
+		HTML: "🎙 \"coffee &lt;12.50&gt;\"\n\n" +
+			"<b>Category:</b> Food\n<b>Subcategory:</b> Shop\n<b>Amount:</b> 12.50\n<b>Merchant:</b> M&amp;M_s\n<a href=\"https://short.example/a\">💾 Save to MoneyWiz</a>\n\n" +
+			"<b>Category:</b> Transport\n<b>Subcategory:</b> Taxi\n<b>Amount:</b> 230.00\n<b>Account:</b> CashUAH\n⚠️ Link not shortened:\n<code>moneywiz://expense?amount=230</code>",
+		MarkdownV2: "🎙 \"coffee <12\\.50\\>\"\n\n" +
+			"*Category:* Food\n*Subcategory:* Shop\n*Amount:* 12\\.50\n*Merchant:* M&M\\_s\n[💾 Save to MoneyWiz](https://short.example/a)\n\n" +
+			"*Category:* Transport\n*Subcategory:* Taxi\n*Amount:* 230\\.00\n*Account:* CashUAH\n⚠️ Link not shortened:\n`moneywiz://expense?amount=230`",
+	}
+	for format, want := range cases {
+		got, err := Render(format, locale.English, EventCash, data)
+		if err != nil || got != want {
+			t.Errorf("%s:\n got %q, %v\nwant %q", format, got, err, want)
+		}
+	}
+}
+
+func TestRender_Candidates(t *testing.T) {
+	data := Mono{Entry: Entry{
+		Category:    "Children",
+		Subcategory: "Vocal",
+		Amount:      40,
+		Refund:      true,
+		Confidence:  0.4,
+		Links: []Link{
+			{Category: "Children", Subcategory: "Vocal", URL: "https://short.example/1", Shortened: true},
+			{Category: "Hobby", Subcategory: "Music", URL: "moneywiz://expense?category=Hobby"},
+		},
+	}}
+	cases := map[Format]string{
+		Text: "Category: Children\nSubcategory: Vocal\nAmount: 40.00\n🔄 Refund\n🤔 Not sure (40%), pick one:\n" +
+			"1. Children / Vocal\nhttps://short.example/1\n2. Hobby / Music\n⚠️ Link not shortened:\nmoneywiz://expense?category=Hobby",
+		HTML: "<b>Category:</b> Children\n<b>Subcategory:</b> Vocal\n<b>Amount:</b> 40.00\n🔄 Refund\n🤔 Not sure (40%), pick one:\n" +
+			"1. <a href=\"https://short.example/1\">Children / Vocal</a>\n2. Hobby / Music\n⚠️ Link not shortened:\n<code>moneywiz://expense?category=Hobby</code>",
+		MarkdownV2: "*Category:* Children\n*Subcategory:* Vocal\n*Amount:* 40\\.00\n🔄 Refund\n🤔 Not sure \\(40%\\), pick one:\n" +
+			"1\\. [Children / Vocal](https://short.example/1)\n2\\. Hobby / Music\n⚠️ Link not shortened:\n`moneywiz://expense?category=Hobby`",
+	}
+	for format, want := range cases {
+		got, err := Render(format, locale.English, EventMono, data)
+		if err != nil || got != want {
+			t.Errorf("%s:\n got %q, %v\nwant %q", format, got, err, want)
+		}
+	}
+}
+
+func TestRender_Ukrainian(t *testing.T) {
+	data := Mono{Entry: Entry{
+		Category:    "Children",
+		Subcategory: "Vocal",
+		Amount:      40,
+		Refund:      true,
+		Confidence:  0.4,
+		Links: []Link{
+			{Category: "Children", Subcategory: "Vocal", URL: "https://short.example/1", Shortened: true},
+			{Category: "Custom", Subcategory: "Thing", URL: "moneywiz://expense?category=Custom"},
+		},
+	}}
+	want := "<b>Категорія:</b> Діти\n<b>Підкатегорія:</b> Вокал\n<b>Сума:</b> 40.00\n🔄 Повернення\n🤔 Не впевнений (40%), оберіть:\n" +
+		"1. <a href=\"https://short.example/1\">Діти / Вокал</a>\n2. Custom / Thing\n⚠️ Посилання не скорочено:\n<code>moneywiz://expense?category=Custom</code>"
+	if got, err := Render(HTML, locale.Ukrainian, EventMono, data); err != nil || got != want {
+		t.Errorf("got %q, %v\nwant %q", got, err, want)
+	}
+
+	want = "Категорія: Їжа\nПідкатегорія: Продукти\nСума: 12.50\nПродавець: M&M_s\nhttps://short.example/a"
+	if got, err := Render(Text, locale.Ukrainian, EventCash, Cash{Entries: []Entry{entry}}); err != nil || got != want {
+		t.Errorf("got %q, %v\nwant %q", got, err, want)
+	}
+}
+
+func TestRender_NotificationErrorAndDigest(t *testing.T) {
+	cases := []struct {
+		format Format
+		event  string
+		data   any
+		want   string
+	}{
+		{HTML, EventNotification, Notification{App: "BBVA <ES>", Entry: entry},
+			"📲 <b>BBVA &lt;ES&gt;</b>\n<b>Category:</b> Food\n<b>Subcategory:</b> Shop\n<b>Amount:</b> 12.50\n<b>Merchant:</b> M&amp;M_s\n<a href=\"https://short.example/a\">💾 Save to MoneyWiz</a>"},
+		{MarkdownV2, EventNotification, Notification{App: "BBVA ES", Entry: entry},
+			"📲 *BBVA ES*\n*Category:* Food\n*Subcategory:* Shop\n*Amount:* 12\\.50\n*Merchant:* M&M\\_s\n[💾 Save to MoneyWiz](https://short.example/a)"},
+		{HTML, EventCash, Cash{Member: "Anna <K>", Entries: []Entry{entry}},
+			"👤 <b>Anna &lt;K&gt;</b>\n<b>Category:</b> Food\n<b>Subcategory:</b> Shop\n<b>Amount:</b> 12.50\n<b>Merchant:</b> M&amp;M_s\n<a href=\"https://short.example/a\">💾 Save to MoneyWiz</a>"},
+		{Text, EventNotification, Notification{App: "ПУМБ", Member: "Anna", Entry: entry},
+			"📲 ПУМБ\n👤 Anna\nCategory: Food\nSubcategory: Shop\nAmount: 12.50\nMerchant: M&M_s\nhttps://short.example/a"},
+		{Text, EventError, Error{Transcript: "hm", Message: "⚠️ AI is unavailable"}, "🎙 \"hm\"\n\n⚠️ AI is unavailable"},
+		{MarkdownV2, EventError, Error{Message: "⏳ Try again in a minute."}, "⏳ Try again in a minute\\."},
+		{HTML, EventDigest, Digest{Title: "MCC codes:", Lines: []string{"1111 × 1 (A&B)"}}, "<b>MCC codes:</b>\n1111 × 1 (A&amp;B)"},
+		{MarkdownV2, EventDigest, Digest{Title: "MCC codes:", Lines: []string{"1111 × 1 (A&B)"}}, "*MCC codes:*\n1111 × 1 \\(A&B\\)"},
+	}
+	for _, c := range cases {
+		got, err := Render(c.format, locale.English, c.event, c.data)
+		if err != nil || got != c.want {
+			t.Errorf("%s %s:\n got %q, %v\nwant %q", c.format, c.event, got, err, c.want)
+		}
+	}
+}
+
+func TestRender_UnknownEvent(t *testing.T) {
+	if _, err := Render(Text, locale.English, "missing", nil); err == nil {
+		t.Error("expected an error for an unknown event")
+	}
+}
@@ -0,0 +1,9 @@
+package speechservice
+
+import "context"
+
+// SpeechService turns recorded speech into text.
+type SpeechService interface {
+	// Transcribe returns the text spoken in audio, whose MIME type is mediaType.
+	Transcribe(audio []byte, mediaType string, ctx *context.Context) (string, error)
+}
@@ -0,0 +1,31 @@
+package storage
+
+// Storage keeps small JSON-encodable values between invocations.
+type Storage interface {
+	// Load decodes the value stored under key into value and reports whether it was found.
+	Load(key string, value any) (bool, error)
+	Save(key string, value any) error
+}
+
+// Updater is a Storage that changes values atomically, for values that
+// several function instances change at once, such as the AI budget.
+type Updater interface {
+	// Update loads the value under key into value, calls change with
+	// whether it was found and saves the result. If another writer saved
+	// the key in between, it starts over from a zeroed value.
+	Update(key string, value any, change func(found bool)) error
+}
+
+// Update changes the value under key with change, atomically when store is
+// an Updater.
+func Update(store Storage, key string, value any, change func(found bool)) error {
+	if updater, ok := store.(Updater); ok {
+		return updater.Update(key, value, change)
+	}
+	found, err := store.Load(key, value)
+	if err != nil {
+		return err
+	}
+	change(found)
+	return store.Save(key, value)
+}
@@ -0,0 +1,7 @@
+package taskservice
+
+// ScheduledDeletion deletes a message the bot sent earlier.
+type ScheduledDeletion struct {
+	ChatID    int64 `json:"chatId"`
+	MessageID int64 `json:"messageId"`
+}
@@ -0,0 +1,12 @@
+package taskservice
+
+// ScheduledEdit replaces the text of a message the bot sent earlier.
+type ScheduledEdit struct {
+	ChatID    int64  `json:"chatId"`
+	MessageID int64  `json:"messageId"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode,omitempty"`
+	// ReplyToMessageID is the message the edited reply answered. If the edit
+	// fails, the text is sent as a new reply to it instead.
+	ReplyToMessageID *int64 `json:"reply_to_message_id,omitempty"`
+}
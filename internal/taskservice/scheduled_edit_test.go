@@ -0,0 +1,28 @@
+package taskservice
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestScheduledEdit_JSONSerialization(t *testing.T) {
+	replyTo := int64(42)
+	edit := ScheduledEdit{ChatID: 777, MessageID: 1001, Text: "coffee 50", ReplyToMessageID: &replyTo}
+
+	data, err := json.Marshal(edit)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	want := `{"chatId":777,"messageId":1001,"text":"coffee 50","reply_to_message_id":42}`
+	if string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+
+	var decoded ScheduledEdit
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if decoded.MessageID != 1001 || decoded.ReplyToMessageID == nil || *decoded.ReplyToMessageID != 42 {
+		t.Errorf("decoded = %+v, want %+v", decoded, edit)
+	}
+}
@@ -3,5 +3,6 @@ package taskservice
 type ScheduledMessage struct {
 	ChatID           int64  `json:"chatId"`
 	Text             string `json:"text"`
+	ParseMode        string `json:"parse_mode,omitempty"`
 	ReplyToMessageID *int64 `json:"reply_to_message_id,omitempty"`
 }
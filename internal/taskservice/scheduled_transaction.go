@@ -9,4 +9,6 @@ type ScheduledTransaction struct {
 	Time      int64   `json:"time"`
 	IsRefund  bool    `json:"isRefund"`
 	AccountID string  `json:"accountId"`
+	// Profile names the owner of the account.
+	Profile string `json:"profile,omitempty"`
 }
@@ -8,6 +8,8 @@ import (
 type TaskService interface {
 	Connect(ctx *context.Context)
 	ScheduleMessage(ctx *context.Context, scheduledMessage ScheduledMessage, timeOffset time.Time)
+	ScheduleEdit(ctx *context.Context, scheduledEdit ScheduledEdit, timeOffset time.Time)
+	ScheduleDeletion(ctx *context.Context, scheduledDeletion ScheduledDeletion, timeOffset time.Time)
 	ScheduleTransaction(ctx *context.Context, scheduledTransaction ScheduledTransaction, timeOffset time.Time)
 	Close()
 }
@@ -1,12 +1,18 @@
 #!/bin/bash
 
-FUNCTIONS=("cashHandler" "monoHandler" "monoWebHook" "sendMessage" "notificationHandler")
+FUNCTIONS=("cashHandler" "monoHandler" "monoWebHook" "sendMessage" "editMessage" "deleteMessage" "notificationHandler" "mccReport")
 RUNTIME="go125"
 PROJECT_ID=$MORPH_PROJECT_ID
 MEMORY="256MB"
 TIMEOUT=180 # 3 minutes
 
-ENV_PARAMS=("MORPH_PROJECT_ID=$MORPH_PROJECT_ID" "MORPH_SERVER_REGION=$MORPH_SERVER_REGION" "CLOUD=true")
+# Every function has its own /tmp, so shared state lives in a bucket.
+if [ -z "$MORPH_STORAGE_BUCKET" ]; then
+  echo "Error: MORPH_STORAGE_BUCKET is not set."
+  exit 1
+fi
+
+ENV_PARAMS=("MORPH_PROJECT_ID=$MORPH_PROJECT_ID" "MORPH_SERVER_REGION=$MORPH_SERVER_REGION" "MORPH_STORAGE_BUCKET=$MORPH_STORAGE_BUCKET" "CLOUD=true")
 ENV_VARS=""
 for PARAM in "${ENV_PARAMS[@]}"; do
   ENV_VARS+="$PARAM,"
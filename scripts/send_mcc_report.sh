@@ -0,0 +1,30 @@
+#!/bin/bash
+
+# Triggers the mccReport function, which sends the unknown MCC codes seen since
+# the previous report to Telegram.
+#
+# Usage:
+#   export MORPH_PROJECT_ID="your-project-id"
+#   export MORPH_SERVER_REGION="us-central1"
+#   ./scripts/send_mcc_report.sh
+
+if [ -z "$MORPH_PROJECT_ID" ]; then
+  echo "Error: MORPH_PROJECT_ID is not set."
+  exit 1
+fi
+
+if [ -z "$MORPH_SERVER_REGION" ]; then
+  echo "Error: MORPH_SERVER_REGION is not set."
+  exit 1
+fi
+
+URL="https://$MORPH_SERVER_REGION-$MORPH_PROJECT_ID.cloudfunctions.net/mccReport"
+
+STATUS=$(curl -s -o /dev/null -w "%{http_code}" -X POST "$URL")
+
+if [ "$STATUS" -eq 200 ]; then
+  echo "MCC report has been sent successfully."
+else
+  echo "Failed to send MCC report (HTTP $STATUS)."
+  exit 1
+fi
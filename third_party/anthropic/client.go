@@ -0,0 +1,220 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+)
+
+const defaultBaseURL = "https://api.anthropic.com"
+const defaultModel = "claude-sonnet-4-5"
+const apiVersion = "2023-06-01"
+const maxTokens = 1024
+
+// Anthropic talks to the Anthropic Messages API. The structured response is
+// obtained by forcing the model to call a single tool whose input schema is
+// aiservice.Response.
+type Anthropic struct {
+	APIKey  string
+	BaseURL string
+	Model   string
+	// Recorder, when set, receives the token usage of every completed call.
+	Recorder aiservice.UsageRecorder
+}
+
+type tool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema interface{} `json:"input_schema"`
+}
+
+type toolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// message content is either a string or a list of contentParts.
+type message struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type imageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+type contentPart struct {
+	Type   string       `json:"type"`
+	Text   string       `json:"text,omitempty"`
+	Source *imageSource `json:"source,omitempty"`
+}
+
+type messagesRequest struct {
+	Model      string     `json:"model"`
+	MaxTokens  int        `json:"max_tokens"`
+	System     string     `json:"system"`
+	Messages   []message  `json:"messages"`
+	Tools      []tool     `json:"tools"`
+	ToolChoice toolChoice `json:"tool_choice"`
+}
+
+type contentBlock struct {
+	Type  string          `json:"type"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+type usage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+}
+
+type messagesResponse struct {
+	Content    []contentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      usage          `json:"usage"`
+}
+
+func (service Anthropic) baseURL() string {
+	if service.BaseURL != "" {
+		return service.BaseURL
+	}
+	return defaultBaseURL
+}
+
+func (service Anthropic) model() string {
+	if service.Model != "" {
+		return service.Model
+	}
+	return defaultModel
+}
+
+func (service Anthropic) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	return service.request(*ctx, name, description, systemPrompt, userPrompt)
+}
+
+// RequestImage sends image as a base64 image block, followed by userPrompt.
+func (service Anthropic) RequestImage(name string, description string, systemPrompt string, userPrompt string, image aiservice.Image, ctx *context.Context) (*aiservice.Response, error) {
+	content := []contentPart{
+		{
+			Type: "image",
+			Source: &imageSource{
+				Type:      "base64",
+				MediaType: image.MediaType,
+				Data:      base64.StdEncoding.EncodeToString(image.Data),
+			},
+		},
+		{Type: "text", Text: userPrompt},
+	}
+	return service.request(*ctx, name, description, systemPrompt, content)
+}
+
+func (service Anthropic) request(ctx context.Context, name string, description string, systemPrompt string, content any) (*aiservice.Response, error) {
+	startTime := time.Now()
+	defer func() {
+		duration := time.Since(startTime)
+		log.Printf("[Anthropic] Request took %v", duration)
+	}()
+
+	result, err := service.send(ctx, name, description, systemPrompt, content)
+	if err != nil {
+		log.Printf("[AI] Error parsing analysis: %s", err.Error())
+		return nil, err
+	}
+
+	if service.Recorder != nil {
+		service.Recorder.Record(aiservice.Usage{
+			Model:            service.model(),
+			PromptTokens:     result.Usage.InputTokens,
+			CompletionTokens: result.Usage.OutputTokens,
+		})
+	}
+
+	if result.StopReason == "refusal" {
+		return nil, aiservice.ErrRefusal
+	}
+
+	for _, block := range result.Content {
+		if block.Type != "tool_use" || block.Name != name {
+			continue
+		}
+		response := aiservice.Response{}
+		if err := json.Unmarshal(block.Input, &response); err != nil {
+			log.Printf("[AI] Error parsing analysis: %s", err.Error())
+			return nil, fmt.Errorf("%w: %v", aiservice.ErrInvalidJSON, err)
+		}
+		return &response, nil
+	}
+
+	log.Printf("[AI] Error parsing analysis: no %s tool call in response", name)
+	return nil, aiservice.ErrEmptyChoices
+}
+
+func (service Anthropic) send(ctx context.Context, name string, description string, systemPrompt string, content any) (*messagesResponse, error) {
+	requestBody := messagesRequest{
+		Model:     service.model(),
+		MaxTokens: maxTokens,
+		System:    systemPrompt,
+		Messages: []message{
+			{Role: "user", Content: content},
+		},
+		Tools: []tool{
+			{
+				Name:        name,
+				Description: description,
+				InputSchema: aiservice.GenerateSchema[aiservice.Response](),
+			},
+		},
+		ToolChoice: toolChoice{Type: "tool", Name: name},
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", service.baseURL()+"/v1/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", service.APIKey)
+	req.Header.Set("anthropic-version", apiVersion)
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return nil, fmt.Errorf("%w: %v", aiservice.ErrTimeout, err)
+		}
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, fmt.Errorf("%w: %s", aiservice.ErrRateLimited, string(body))
+		}
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result messagesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,115 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/morph/internal/aiservice"
+)
+
+func TestRequest_ParsesToolCall(t *testing.T) {
+	var got messagesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("Expected path /v1/messages, got %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("Expected api key header, got %q", r.Header.Get("x-api-key"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		w.Write([]byte(`{"content":[{"type":"text","text":"Sure"},{"type":"tool_use","name":"Morph","input":{"category":"Food","subcategory":"Shop","amount":42.5,"isTransaction":true}}]}`))
+	}))
+	defer server.Close()
+
+	service := Anthropic{APIKey: "test-key", BaseURL: server.URL, Model: "test-model"}
+	ctx := context.Background()
+	response, err := service.Request("Morph", "Classify", "system", "user", &ctx)
+
+	if err != nil {
+		t.Fatalf("Expected response, got %v", err)
+	}
+	if response.Category != "Food" || response.Subcategory != "Shop" || response.Amount != 42.5 || !response.IsTransaction {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	if got.Model != "test-model" || got.System != "system" || got.ToolChoice.Name != "Morph" {
+		t.Errorf("Unexpected request: %+v", got)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Content != "user" {
+		t.Errorf("Unexpected messages: %+v", got.Messages)
+	}
+}
+
+func TestRequest_NoToolCallReturnsEmptyChoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[{"type":"text","text":"I cannot help with that"}]}`))
+	}))
+	defer server.Close()
+
+	service := Anthropic{BaseURL: server.URL}
+	ctx := context.Background()
+	if _, err := service.Request("Morph", "Classify", "system", "user", &ctx); !errors.Is(err, aiservice.ErrEmptyChoices) {
+		t.Errorf("Expected ErrEmptyChoices, got %v", err)
+	}
+}
+
+func TestRequest_RefusalReturnsErrRefusal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[],"stop_reason":"refusal"}`))
+	}))
+	defer server.Close()
+
+	service := Anthropic{BaseURL: server.URL}
+	ctx := context.Background()
+	if _, err := service.Request("Morph", "Classify", "system", "user", &ctx); !errors.Is(err, aiservice.ErrRefusal) {
+		t.Errorf("Expected ErrRefusal, got %v", err)
+	}
+}
+
+func TestRequest_TooManyRequestsReturnsErrRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"type":"error"}`))
+	}))
+	defer server.Close()
+
+	service := Anthropic{BaseURL: server.URL}
+	ctx := context.Background()
+	if _, err := service.Request("Morph", "Classify", "system", "user", &ctx); !errors.Is(err, aiservice.ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestRequestImage_SendsImageBlock(t *testing.T) {
+	var got struct {
+		Messages []struct {
+			Content []contentPart `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		w.Write([]byte(`{"content":[{"type":"tool_use","name":"Morph","input":{"category":"Food","amount":312.4,"merchant":"Silpo","isTransaction":true}}]}`))
+	}))
+	defer server.Close()
+
+	service := Anthropic{BaseURL: server.URL}
+	ctx := context.Background()
+	response, err := service.RequestImage("Morph", "Classify", "system", "receipt", aiservice.Image{Data: []byte("jpeg"), MediaType: "image/jpeg"}, &ctx)
+	if err != nil {
+		t.Fatalf("Expected response, got %v", err)
+	}
+	if response.Merchant != "Silpo" || response.Amount != 312.4 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	content := got.Messages[0].Content
+	if len(content) != 2 || content[0].Type != "image" || content[0].Source.MediaType != "image/jpeg" || content[0].Source.Data != "anBlZw==" || content[1].Text != "receipt" {
+		t.Errorf("Unexpected content: %+v", content)
+	}
+}
@@ -0,0 +1,81 @@
+package filestore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore keeps every key as a JSON file in MORPH_STORAGE_DIR. Point it at a
+// mounted bucket to share state between function instances; it falls back to
+// the system temp directory otherwise.
+type FileStore struct{}
+
+func directory() string {
+	if dir := os.Getenv("MORPH_STORAGE_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "morph")
+}
+
+func path(key string) string {
+	return filepath.Join(directory(), key+".json")
+}
+
+func (store FileStore) Load(key string, value any) (bool, error) {
+	data, err := os.ReadFile(path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (store FileStore) Save(key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(directory(), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial value.
+	tmp, err := os.CreateTemp(directory(), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path(key))
+}
+
+// updates serializes Update, as the files are only shared by the goroutines
+// of one process.
+var updates sync.Mutex
+
+func (store FileStore) Update(key string, value any, change func(found bool)) error {
+	updates.Lock()
+	defer updates.Unlock()
+
+	found, err := store.Load(key, value)
+	if err != nil {
+		return err
+	}
+	change(found)
+	return store.Save(key, value)
+}
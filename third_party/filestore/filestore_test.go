@@ -0,0 +1,59 @@
+package filestore
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestFileStore_SaveAndLoad(t *testing.T) {
+	t.Setenv("MORPH_STORAGE_DIR", t.TempDir())
+	store := FileStore{}
+
+	var missing map[string]int
+	found, err := store.Load("counters", &missing)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if found {
+		t.Fatal("Expected missing key to be reported as not found")
+	}
+
+	if err := store.Save("counters", map[string]int{"a": 1, "b": 2}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var got map[string]int
+	found, err = store.Load("counters", &got)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !found {
+		t.Fatal("Expected key to be found")
+	}
+	if got["a"] != 1 || got["b"] != 2 {
+		t.Errorf("Expected {a:1 b:2}, got %v", got)
+	}
+}
+
+func TestFileStore_Update(t *testing.T) {
+	t.Setenv("MORPH_STORAGE_DIR", t.TempDir())
+	store := FileStore{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var count int
+			if err := store.Update("count", &count, func(found bool) { count++ }); err != nil {
+				t.Errorf("Update: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var count int
+	if _, err := store.Load("count", &count); err != nil || count != 8 {
+		t.Errorf("count = %d, %v, want 8", count, err)
+	}
+}
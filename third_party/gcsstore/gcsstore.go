@@ -0,0 +1,134 @@
+package gcsstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+
+	"golang.org/x/oauth2/google"
+)
+
+// GCSStore keeps every key as a JSON object in a Cloud Storage bucket, so
+// every function and instance shares the same state.
+type GCSStore struct {
+	Bucket string
+}
+
+var apiURL = "https://storage.googleapis.com"
+
+// client authenticates with the function's service account, or locally with
+// the application default credentials.
+var client = sync.OnceValues(func() (*http.Client, error) {
+	return google.DefaultClient(context.Background(), "https://www.googleapis.com/auth/devstorage.read_write")
+})
+
+func object(key string) string {
+	return key + ".json"
+}
+
+// maxUpdateAttempts bounds how often Update starts over when other writers
+// keep changing the key.
+const maxUpdateAttempts = 10
+
+// errConflict is returned by save when the object changed since it was
+// loaded.
+var errConflict = errors.New("changed by another writer")
+
+func (store GCSStore) Load(key string, value any) (bool, error) {
+	_, found, err := store.load(key, value)
+	return found, err
+}
+
+func (store GCSStore) Save(key string, value any) error {
+	return store.save(key, value, "")
+}
+
+// Update uses the generation of the object, which changes on every write, as
+// a precondition of the save.
+func (store GCSStore) Update(key string, value any, change func(found bool)) error {
+	for attempt := 1; ; attempt++ {
+		reflect.ValueOf(value).Elem().SetZero()
+		generation, found, err := store.load(key, value)
+		if err != nil {
+			return err
+		}
+		if !found {
+			// Generation 0 means the object must not exist yet.
+			generation = "0"
+		}
+		change(found)
+		err = store.save(key, value, generation)
+		if !errors.Is(err, errConflict) || attempt == maxUpdateAttempts {
+			return err
+		}
+		log.Printf("[Storage] %s was %s, updating it again", key, errConflict)
+	}
+}
+
+// load decodes the object of key into value and returns its generation.
+func (store GCSStore) load(key string, value any) (string, bool, error) {
+	httpClient, err := client()
+	if err != nil {
+		return "", false, fmt.Errorf("storage credentials: %v", err)
+	}
+	resp, err := httpClient.Get(fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", apiURL, url.PathEscape(store.Bucket), url.PathEscape(object(key))))
+	if err != nil {
+		return "", false, fmt.Errorf("could not load %s: %v", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", false, fmt.Errorf("could not load %s: %s %s", key, resp.Status, body)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(value); err != nil {
+		return "", false, err
+	}
+	return resp.Header.Get("X-Goog-Generation"), true, nil
+}
+
+// save uploads value as the object of key. A non-empty generation makes the
+// upload fail with errConflict unless the object is still at it.
+func (store GCSStore) save(key string, value any, generation string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	httpClient, err := client()
+	if err != nil {
+		return fmt.Errorf("storage credentials: %v", err)
+	}
+
+	// A media upload replaces the object at once, so readers never see a
+	// partial value.
+	query := url.Values{"uploadType": {"media"}, "name": {object(key)}}
+	if generation != "" {
+		query.Set("ifGenerationMatch", generation)
+	}
+	uploadURL := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", apiURL, url.PathEscape(store.Bucket), query.Encode())
+	resp, err := httpClient.Post(uploadURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("could not save %s: %v", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return errConflict
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("could not save %s: %s %s", key, resp.Status, body)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package gcsstore
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeBucket serves the Cloud Storage JSON API calls GCSStore makes from
+// memory.
+func fakeBucket(t *testing.T) map[string]string {
+	t.Helper()
+	objects := map[string]string{}
+	generations := map[string]int{}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/storage/v1/b/state/o/"):
+			name := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/state/o/")
+			data, ok := objects[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("X-Goog-Generation", strconv.Itoa(generations[name]))
+			w.Write([]byte(data))
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/state/o":
+			name := r.URL.Query().Get("name")
+			if match := r.URL.Query().Get("ifGenerationMatch"); match != "" && match != strconv.Itoa(generations[name]) {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			data, _ := io.ReadAll(r.Body)
+			objects[name] = string(data)
+			generations[name]++
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"code":403}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	previousURL, previousClient := apiURL, client
+	apiURL = server.URL
+	client = func() (*http.Client, error) { return server.Client(), nil }
+	t.Cleanup(func() { apiURL, client = previousURL, previousClient })
+	return objects
+}
+
+func TestGCSStore_SaveAndLoad(t *testing.T) {
+	objects := fakeBucket(t)
+	store := GCSStore{Bucket: "state"}
+
+	var missing map[string]int
+	found, err := store.Load("counters", &missing)
+	if err != nil || found {
+		t.Fatalf("Load of a missing key = %v, %v, want not found", found, err)
+	}
+
+	if err := store.Save("counters", map[string]int{"a": 1, "b": 2}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if objects["counters.json"] != `{"a":1,"b":2}` {
+		t.Errorf("objects = %v", objects)
+	}
+
+	var got map[string]int
+	found, err = store.Load("counters", &got)
+	if err != nil || !found || got["a"] != 1 || got["b"] != 2 {
+		t.Errorf("Load = %v, %v, %v", got, found, err)
+	}
+}
+
+func TestGCSStore_ErrorStatus(t *testing.T) {
+	fakeBucket(t)
+	store := GCSStore{Bucket: "other"}
+
+	var value map[string]int
+	if _, err := store.Load("counters", &value); err == nil {
+		t.Error("expected an error for a failed load")
+	}
+	if err := store.Save("counters", value); err == nil {
+		t.Error("expected an error for a failed save")
+	}
+}
+
+func TestGCSStore_UpdateIsAtomic(t *testing.T) {
+	objects := fakeBucket(t)
+	store := GCSStore{Bucket: "state"}
+
+	const writers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var counter struct{ Count int }
+			err := store.Update("counter", &counter, func(found bool) {
+				counter.Count++
+			})
+			if err != nil {
+				t.Errorf("Update: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if objects["counter.json"] != `{"Count":8}` {
+		t.Errorf("counter = %s, want every update counted", objects["counter.json"])
+	}
+}
@@ -37,6 +37,19 @@ func (tasks GoogleTasks) ScheduleMessage(ctx *context.Context, scheduledMessage
 	scheduleTask(ctx, queuePath, url, scheduledMessage, timeOffset)
 }
 
+// ScheduleEdit uses the messages queue too. Cloud Tasks doesn't keep the
+// order of a queue; edits are only scheduled for replies already sent, and
+// editMessage sends the text as a new reply when the edit fails.
+func (tasks GoogleTasks) ScheduleEdit(ctx *context.Context, scheduledEdit taskservice.ScheduledEdit, timeOffset time.Time) {
+	queuePath, url := prepareURLs("messages", "editMessage")
+	scheduleTask(ctx, queuePath, url, scheduledEdit, timeOffset)
+}
+
+func (tasks GoogleTasks) ScheduleDeletion(ctx *context.Context, scheduledDeletion taskservice.ScheduledDeletion, timeOffset time.Time) {
+	queuePath, url := prepareURLs("messages", "deleteMessage")
+	scheduleTask(ctx, queuePath, url, scheduledDeletion, timeOffset)
+}
+
 func (tasks GoogleTasks) ScheduleTransaction(ctx *context.Context, scheduledTransaction taskservice.ScheduledTransaction, timeOffset time.Time) {
 	queuePath, url := prepareURLs("transactions", "monoHandler")
 	scheduleTask(ctx, queuePath, url, scheduledTransaction, timeOffset)
@@ -11,6 +11,16 @@ type DeepLinkGenerator struct{}
 // Create builds a MoneyWiz deep link for an expense.
 // The date parameter represents the transaction date and will be formatted as YYYY-MM-DD.
 func (g DeepLinkGenerator) Create(category string, subcategory string, account string, amount float64, date time.Time) string {
+	return build(category, subcategory, account, amount, date) + "&save=true"
+}
+
+// CreateDraft builds the same link as Create without save=true, so MoneyWiz
+// opens the prefilled expense and waits for confirmation.
+func (g DeepLinkGenerator) CreateDraft(category string, subcategory string, account string, amount float64, date time.Time) string {
+	return build(category, subcategory, account, amount, date)
+}
+
+func build(category string, subcategory string, account string, amount float64, date time.Time) string {
 	finalizedCategory := category
 	if subcategory != "" {
 		finalizedCategory += "/" + subcategory
@@ -31,7 +41,7 @@ func (g DeepLinkGenerator) Create(category string, subcategory string, account s
 	formattedDate = strings.ReplaceAll(formattedDate, " ", "%20")
 
 	return fmt.Sprintf(
-		"moneywiz://expense?amount=%.2f&account=%s&category=%s&date=%s&save=true",
+		"moneywiz://expense?amount=%.2f&account=%s&category=%s&date=%s",
 		amount,
 		account,
 		finalizedCategory,
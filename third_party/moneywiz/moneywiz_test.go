@@ -57,3 +57,14 @@ func TestDeepLinkGenerator_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestDeepLinkGenerator_CreateDraft(t *testing.T) {
+	generator := DeepLinkGenerator{}
+	date := time.Date(2024, 12, 1, 14, 30, 45, 0, time.UTC)
+
+	got := generator.CreateDraft("Food", "Groceries", "Cash", 42.50, date)
+	want := "moneywiz://expense?amount=42.50&account=Cash&category=Food/Groceries&date=2024-12-01%2016:30:45"
+	if got != want {
+		t.Errorf("DeepLinkGenerator.CreateDraft() = %v, want %v", got, want)
+	}
+}
@@ -2,46 +2,132 @@ package openai
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
-	"os"
+	"net/http"
 	"time"
 
-	"github.com/invopop/jsonschema"
 	"github.com/morph/internal/aiservice"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
 )
 
-type OpenAI struct{}
+// Config configures the OpenAI client. BaseURL points it at any server
+// exposing the same API (Ollama, llama.cpp).
+type Config struct {
+	APIKey  string
+	BaseURL string
+	Model   string
+	// FallbackModels are tried in order when the primary model errors or times out.
+	FallbackModels []string
+	// Temperature is left to the API default when nil.
+	Temperature *float64
+	// VisionModel, when set, reads images instead of Model and FallbackModels.
+	VisionModel string
+	// Timeout bounds every model attempt separately; zero means no timeout.
+	Timeout time.Duration
+	// Recorder, when set, receives the token usage of every completed call.
+	Recorder aiservice.UsageRecorder
+}
+
+// OpenAI talks to the OpenAI chat completions API through a single client
+// created in New and reused for every request.
+type OpenAI struct {
+	config Config
+	client *openai.Client
+}
 
-func createAI() *openai.Client {
-	client := openai.NewClient(
-		option.WithAPIKey(os.Getenv("MORPH_AI_KEY")),
-	)
-	return &client
+func New(config Config) *OpenAI {
+	options := []option.RequestOption{
+		option.WithAPIKey(config.APIKey),
+	}
+	if config.BaseURL != "" {
+		options = append(options, option.WithBaseURL(config.BaseURL))
+	}
+	client := openai.NewClient(options...)
+	return &OpenAI{config: config, client: &client}
 }
 
-func generateSchema[T any]() interface{} {
-	reflector := jsonschema.Reflector{
-		AllowAdditionalProperties: false,
-		DoNotReference:            true,
+// Config returns the configuration the client was created with.
+func (service *OpenAI) Config() Config {
+	return service.config
+}
+
+// models lists the primary model followed by the fallbacks.
+func (service *OpenAI) models() []string {
+	primary := service.config.Model
+	if primary == "" {
+		primary = openai.ChatModelGPT4o
+	}
+	return append([]string{primary}, service.config.FallbackModels...)
+}
+
+func (service *OpenAI) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) (*aiservice.Response, error) {
+	return service.requestModels(*ctx, service.models(), name, description, systemPrompt, openai.UserMessage(userPrompt))
+}
+
+// RequestImage sends image inline as a base64 data URL, followed by userPrompt.
+func (service *OpenAI) RequestImage(name string, description string, systemPrompt string, userPrompt string, image aiservice.Image, ctx *context.Context) (*aiservice.Response, error) {
+	models := service.models()
+	if service.config.VisionModel != "" {
+		models = []string{service.config.VisionModel}
+	}
+	dataURL := "data:" + image.MediaType + ";base64," + base64.StdEncoding.EncodeToString(image.Data)
+	user := openai.UserMessage([]openai.ChatCompletionContentPartUnionParam{
+		openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: dataURL}),
+		openai.TextContentPart(userPrompt),
+	})
+	return service.requestModels(*ctx, models, name, description, systemPrompt, user)
+}
+
+// requestModels tries models in order and returns the first response, or the last error.
+func (service *OpenAI) requestModels(ctx context.Context, models []string, name string, description string, systemPrompt string, user openai.ChatCompletionMessageParamUnion) (*aiservice.Response, error) {
+	var lastErr error
+	for _, model := range models {
+		response, err := service.request(ctx, model, name, description, systemPrompt, user)
+		if err == nil {
+			return response, nil
+		}
+		log.Printf("[AI] Error parsing analysis with model %s: %s", model, err.Error())
+		lastErr = err
+
+		// Nothing else can succeed once the caller's context is done.
+		if ctx.Err() != nil {
+			break
+		}
 	}
-	var v T
-	schema := reflector.Reflect(v)
-	return schema
+	return nil, lastErr
 }
 
-func (service OpenAI) Request(name string, description string, systemPrompt string, userPrompt string, ctx *context.Context) *aiservice.Response {
+// classifyError maps client errors to the aiservice errors.
+func classifyError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", aiservice.ErrTimeout, err)
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %v", aiservice.ErrRateLimited, err)
+	}
+	return err
+}
+
+func (service *OpenAI) request(ctx context.Context, model string, name string, description string, systemPrompt string, user openai.ChatCompletionMessageParamUnion) (*aiservice.Response, error) {
 	startTime := time.Now()
 	defer func() {
 		duration := time.Since(startTime)
-		log.Printf("[OpenAI] Request took %v", duration)
+		log.Printf("[OpenAI] Request to %s took %v", model, duration)
 	}()
 
-	ai := createAI()
+	if service.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, service.config.Timeout)
+		defer cancel()
+	}
 
-	var responseSchema = generateSchema[aiservice.Response]()
+	var responseSchema = aiservice.GenerateSchema[aiservice.Response]()
 
 	schemaParam := openai.ResponseFormatJSONSchemaJSONSchemaParam{
 		Name:        name,
@@ -50,30 +136,47 @@ func (service OpenAI) Request(name string, description string, systemPrompt stri
 		Strict:      openai.Bool(true),
 	}
 
-	chat, err := ai.Chat.Completions.New(*ctx, openai.ChatCompletionNewParams{
+	params := openai.ChatCompletionNewParams{
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.SystemMessage(systemPrompt),
-			openai.UserMessage(userPrompt),
+			user,
 		},
 		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
 			OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
 				JSONSchema: schemaParam,
 			},
 		},
-		Model: openai.ChatModelGPT4o,
-	})
+		Model: model,
+	}
+	if service.config.Temperature != nil {
+		params.Temperature = openai.Float(*service.config.Temperature)
+	}
 
-	response := aiservice.Response{}
+	chat, err := service.client.Chat.Completions.New(ctx, params)
 	if err != nil {
-		log.Printf("[AI] Error parsing analysis: %s", err.Error())
-		return nil
+		return nil, classifyError(err)
 	}
 
-	err = json.Unmarshal([]byte(chat.Choices[0].Message.Content), &response)
-	if err != nil {
-		log.Printf("[AI] Error parsing analysis: %s", err.Error())
-		return nil
+	if service.config.Recorder != nil {
+		service.config.Recorder.Record(aiservice.Usage{
+			Model:            model,
+			PromptTokens:     chat.Usage.PromptTokens,
+			CompletionTokens: chat.Usage.CompletionTokens,
+		})
+	}
+
+	if len(chat.Choices) == 0 {
+		return nil, aiservice.ErrEmptyChoices
+	}
+	message := chat.Choices[0].Message
+	if message.Refusal != "" {
+		return nil, fmt.Errorf("%w: %s", aiservice.ErrRefusal, message.Refusal)
+	}
+
+	response := aiservice.Response{}
+	if err := json.Unmarshal([]byte(message.Content), &response); err != nil {
+		return nil, fmt.Errorf("%w: %v", aiservice.ErrInvalidJSON, err)
 	}
 
-	return &response
+	return &response, nil
 }
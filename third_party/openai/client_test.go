@@ -0,0 +1,163 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+)
+
+type usageLog struct {
+	usages []aiservice.Usage
+}
+
+func (l *usageLog) Record(usage aiservice.Usage) {
+	l.usages = append(l.usages, usage)
+}
+
+type chatRequest struct {
+	Model       string   `json:"model"`
+	Temperature *float64 `json:"temperature"`
+}
+
+func chatResponse(content string) string {
+	body, _ := json.Marshal(map[string]any{
+		"id":      "chatcmpl-1",
+		"object":  "chat.completion",
+		"created": 1,
+		"model":   "test",
+		"usage":   map[string]any{"prompt_tokens": 120, "completion_tokens": 15, "total_tokens": 135},
+		"choices": []map[string]any{
+			{
+				"index":         0,
+				"finish_reason": "stop",
+				"message":       map[string]any{"role": "assistant", "content": content},
+			},
+		},
+	})
+	return string(body)
+}
+
+func TestRequest_FallsBackToNextModel(t *testing.T) {
+	var models []string
+	var temperature *float64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		models = append(models, req.Model)
+		temperature = req.Temperature
+
+		if req.Model == "primary" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"model unavailable"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse(`{"category":"Food","subcategory":"Shop","amount":12,"isTransaction":true}`)))
+	}))
+	defer server.Close()
+
+	temp := 0.2
+	recorder := &usageLog{}
+	service := New(Config{
+		Recorder:       recorder,
+		APIKey:         "key",
+		BaseURL:        server.URL,
+		Model:          "primary",
+		FallbackModels: []string{"secondary", "tertiary"},
+		Temperature:    &temp,
+		Timeout:        5 * time.Second,
+	})
+	ctx := context.Background()
+	response, err := service.Request("Morph", "Classify", "system", "user", &ctx)
+
+	if err != nil {
+		t.Fatalf("Expected response, got %v", err)
+	}
+	if response.Category != "Food" || response.Amount != 12 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	if len(models) != 2 || models[0] != "primary" || models[1] != "secondary" {
+		t.Errorf("Expected primary then secondary, got %v", models)
+	}
+	if temperature == nil || *temperature != 0.2 {
+		t.Errorf("Expected temperature 0.2, got %v", temperature)
+	}
+	if len(recorder.usages) != 1 || recorder.usages[0] != (aiservice.Usage{Model: "secondary", PromptTokens: 120, CompletionTokens: 15}) {
+		t.Errorf("Expected usage of the secondary model, got %+v", recorder.usages)
+	}
+}
+
+func TestRequest_AllModelsFailReturnsLastError(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"bad request"}}`))
+	}))
+	defer server.Close()
+
+	service := New(Config{BaseURL: server.URL, Model: "primary", FallbackModels: []string{"secondary"}})
+	ctx := context.Background()
+	if response, err := service.Request("Morph", "Classify", "system", "user", &ctx); err == nil {
+		t.Errorf("Expected error, got %+v", response)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
+
+func TestRequest_InvalidJSONReturnsErrInvalidJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse(`not json`)))
+	}))
+	defer server.Close()
+
+	service := New(Config{BaseURL: server.URL, Model: "primary"})
+	ctx := context.Background()
+	if _, err := service.Request("Morph", "Classify", "system", "user", &ctx); !errors.Is(err, aiservice.ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
+
+func TestRequestImage_UsesVisionModelAndDataURL(t *testing.T) {
+	var got struct {
+		Model    string `json:"model"`
+		Messages []struct {
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse(`{"category":"Food","subcategory":"Shop","amount":99,"merchant":"ATB","isTransaction":true}`)))
+	}))
+	defer server.Close()
+
+	service := New(Config{BaseURL: server.URL, Model: "text-only", VisionModel: "vision"})
+	ctx := context.Background()
+	response, err := service.RequestImage("Morph", "Classify", "system", "receipt", aiservice.Image{Data: []byte("jpeg"), MediaType: "image/jpeg"}, &ctx)
+	if err != nil {
+		t.Fatalf("Expected response, got %v", err)
+	}
+	if response.Merchant != "ATB" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	if got.Model != "vision" {
+		t.Errorf("Expected vision model, got %s", got.Model)
+	}
+	if len(got.Messages) != 2 || !strings.Contains(string(got.Messages[1].Content), "data:image/jpeg;base64,anBlZw==") {
+		t.Errorf("Expected image data URL in user message, got %+v", got.Messages)
+	}
+}
@@ -0,0 +1,91 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/morph/internal/aiservice"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// TranscriberConfig configures the Transcriber. BaseURL points it at any
+// server exposing the same API, such as a local Whisper server.
+type TranscriberConfig struct {
+	APIKey  string
+	BaseURL string
+	// Model defaults to whisper-1.
+	Model string
+	// Language is an optional ISO 639-1 hint, e.g. "uk".
+	Language string
+	// Timeout bounds every request; zero means no timeout.
+	Timeout time.Duration
+	// Recorder, when set, receives the usage of every transcription.
+	Recorder aiservice.UsageRecorder
+}
+
+// Transcriber uses the OpenAI audio transcriptions API as a speech-to-text backend.
+type Transcriber struct {
+	config TranscriberConfig
+	client *openai.Client
+}
+
+func NewTranscriber(config TranscriberConfig) *Transcriber {
+	options := []option.RequestOption{
+		option.WithAPIKey(config.APIKey),
+	}
+	if config.BaseURL != "" {
+		options = append(options, option.WithBaseURL(config.BaseURL))
+	}
+	client := openai.NewClient(options...)
+	return &Transcriber{config: config, client: &client}
+}
+
+func (transcriber *Transcriber) model() string {
+	if transcriber.config.Model != "" {
+		return transcriber.config.Model
+	}
+	return openai.AudioModelWhisper1
+}
+
+func (transcriber *Transcriber) Transcribe(audio []byte, mediaType string, ctx *context.Context) (string, error) {
+	startTime := time.Now()
+	defer func() {
+		log.Printf("[OpenAI] Transcription with %s took %v", transcriber.model(), time.Since(startTime))
+	}()
+
+	requestCtx := *ctx
+	if transcriber.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		requestCtx, cancel = context.WithTimeout(requestCtx, transcriber.config.Timeout)
+		defer cancel()
+	}
+
+	params := openai.AudioTranscriptionNewParams{
+		// Telegram voice notes are OGG/Opus; the file name tells the API the format.
+		File:  openai.File(bytes.NewReader(audio), "voice.ogg", mediaType),
+		Model: transcriber.model(),
+	}
+	if transcriber.config.Language != "" {
+		params.Language = openai.String(transcriber.config.Language)
+	}
+
+	transcription, err := transcriber.client.Audio.Transcriptions.New(requestCtx, params)
+	if err != nil {
+		return "", fmt.Errorf("transcription failed: %w", classifyError(err))
+	}
+
+	if transcriber.config.Recorder != nil {
+		usage := transcription.Usage
+		transcriber.config.Recorder.Record(aiservice.Usage{
+			Model:            transcriber.model(),
+			PromptTokens:     usage.InputTokens,
+			CompletionTokens: usage.OutputTokens,
+			AudioSeconds:     usage.Seconds,
+		})
+	}
+	return transcription.Text, nil
+}
@@ -0,0 +1,48 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTranscribe_SendsAudioAndReturnsText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/transcriptions" {
+			t.Errorf("Expected path /audio/transcriptions, got %s", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Failed to parse form: %v", err)
+		}
+		if r.FormValue("model") != "whisper-1" || r.FormValue("language") != "uk" {
+			t.Errorf("Unexpected form: model=%q language=%q", r.FormValue("model"), r.FormValue("language"))
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("Expected file, got %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		if string(data) != "OggS" || header.Filename != "voice.ogg" {
+			t.Errorf("Unexpected file %s: %q", header.Filename, data)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":"кава сорок гривень","usage":{"type":"duration","seconds":4}}`))
+	}))
+	defer server.Close()
+
+	recorder := &usageLog{}
+	transcriber := NewTranscriber(TranscriberConfig{BaseURL: server.URL, Language: "uk", Recorder: recorder})
+	ctx := context.Background()
+	text, err := transcriber.Transcribe([]byte("OggS"), "audio/ogg", &ctx)
+	if err != nil {
+		t.Fatalf("Expected transcript, got %v", err)
+	}
+	if text != "кава сорок гривень" {
+		t.Errorf("Expected transcript, got %q", text)
+	}
+	if len(recorder.usages) != 1 || recorder.usages[0].Model != "whisper-1" || recorder.usages[0].AudioSeconds != 4 {
+		t.Errorf("Expected the audio length to be recorded, got %+v", recorder.usages)
+	}
+}
@@ -2,4 +2,13 @@ package telegram
 
 type Chat struct {
 	ID int64 `json:"id"`
+	// Type is "private", "group", "supergroup" or "channel".
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// isGroup reports whether the chat is shared with other people.
+func (chat *Chat) isGroup() bool {
+	return chat.Type == "group" || chat.Type == "supergroup"
 }
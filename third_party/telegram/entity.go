@@ -0,0 +1,60 @@
+package telegram
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// MessageEntity marks a special part of a message text, such as a bot
+// command or a mention. Offset and Length count UTF-16 code units. User is
+// the user of a "text_mention", which names users without a username.
+type MessageEntity struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	User   *User  `json:"user,omitempty"`
+}
+
+// command returns the bot command the message starts with, lowercased and
+// without the slash, the bot the command is addressed to with "@bot", if
+// any, and the text after it.
+func (message *Message) command() (string, string, string) {
+	for _, entity := range message.Entities {
+		if entity.Type != "bot_command" || entity.Offset != 0 {
+			continue
+		}
+		text := utf16.Encode([]rune(message.Text))
+		if entity.Length < 2 || entity.Length > len(text) {
+			return "", "", ""
+		}
+		name, bot, _ := strings.Cut(string(utf16.Decode(text[1:entity.Length])), "@")
+		args := strings.TrimSpace(string(utf16.Decode(text[entity.Length:])))
+		return strings.ToLower(name), bot, args
+	}
+	return "", "", ""
+}
+
+// withoutMention looks for a mention of bot in text, marked by entities,
+// and returns text without it.
+func withoutMention(text string, entities []MessageEntity, bot *User) (string, bool) {
+	units := utf16.Encode([]rune(text))
+	for _, entity := range entities {
+		if entity.Offset < 0 || entity.Length <= 0 || entity.Offset+entity.Length > len(units) {
+			continue
+		}
+		mentioned := string(utf16.Decode(units[entity.Offset : entity.Offset+entity.Length]))
+		switch {
+		case entity.Type == "mention" && bot.Username != "" && strings.EqualFold(mentioned, "@"+bot.Username):
+		case entity.Type == "text_mention" && entity.User != nil && entity.User.ID == bot.ID:
+		default:
+			continue
+		}
+		before := strings.TrimSpace(string(utf16.Decode(units[:entity.Offset])))
+		after := strings.TrimSpace(string(utf16.Decode(units[entity.Offset+entity.Length:])))
+		if before == "" || after == "" {
+			return before + after, true
+		}
+		return before + " " + after, true
+	}
+	return text, false
+}
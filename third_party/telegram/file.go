@@ -0,0 +1,52 @@
+package telegram
+
+import "strings"
+
+// PhotoSize is one size of a photo; Telegram lists them smallest first.
+type PhotoSize struct {
+	FileID   string `json:"file_id"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	FileSize int64  `json:"file_size,omitempty"`
+}
+
+// Document is a file sent as-is, e.g. an uncompressed photo.
+type Document struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileSize int64  `json:"file_size,omitempty"`
+}
+
+// Voice is a voice note, recorded in Telegram as OGG/Opus.
+type Voice struct {
+	FileID   string `json:"file_id"`
+	Duration int    `json:"duration"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileSize int64  `json:"file_size,omitempty"`
+}
+
+// File is the getFile result, pointing at where the file can be downloaded.
+type File struct {
+	FileID   string `json:"file_id"`
+	FileSize int64  `json:"file_size,omitempty"`
+	FilePath string `json:"file_path,omitempty"`
+}
+
+type getFileResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description,omitempty"`
+	Result      *File  `json:"result,omitempty"`
+}
+
+// imageFileID returns the file ID of the largest photo, or of a document
+// that is an image, so both compressed and uncompressed photos are read.
+func (message *Message) imageFileID() string {
+	if len(message.Photo) > 0 {
+		return message.Photo[len(message.Photo)-1].FileID
+	}
+	if message.Document != nil && strings.HasPrefix(message.Document.MimeType, "image/") {
+		return message.Document.FileID
+	}
+	return ""
+}
@@ -1,9 +1,18 @@
 package telegram
 
 type Message struct {
-	ID   int64  `json:"message_id"`
-	Text string `json:"text,omitempty"`
-	Chat *Chat  `json:"chat"`
-	From *User  `json:"from,omitempty"`
-	Date int    `json:"date"`
+	ID       int64           `json:"message_id"`
+	Text     string          `json:"text,omitempty"`
+	Caption  string          `json:"caption,omitempty"`
+	Entities []MessageEntity `json:"entities,omitempty"`
+	// CaptionEntities mark special parts of the caption.
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
+	Photo           []PhotoSize     `json:"photo,omitempty"`
+	Document        *Document       `json:"document,omitempty"`
+	Voice           *Voice          `json:"voice,omitempty"`
+	Chat            *Chat           `json:"chat"`
+	From            *User           `json:"from,omitempty"`
+	Date            int             `json:"date"`
+	// ForwardOrigin describes where a forwarded message was first sent.
+	ForwardOrigin *MessageOrigin `json:"forward_origin,omitempty"`
 }
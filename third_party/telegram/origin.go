@@ -0,0 +1,45 @@
+package telegram
+
+// MessageOrigin is the origin of a forwarded message: a user ("user"), a
+// user who hides their account ("hidden_user"), a chat ("chat") or a channel
+// ("channel").
+type MessageOrigin struct {
+	Type           string `json:"type"`
+	Date           int    `json:"date"`
+	SenderUser     *User  `json:"sender_user,omitempty"`
+	SenderUserName string `json:"sender_user_name,omitempty"`
+	SenderChat     *Chat  `json:"sender_chat,omitempty"`
+	Chat           *Chat  `json:"chat,omitempty"`
+}
+
+// person reports whether the original sender is a person rather than a bot,
+// a chat or a channel.
+func (origin *MessageOrigin) person() bool {
+	switch origin.Type {
+	case "hidden_user":
+		return true
+	case "user":
+		return origin.SenderUser == nil || !origin.SenderUser.IsBot
+	}
+	return false
+}
+
+// name is how the original sender is shown, such as the name of a bank's
+// bot. Origins without a name give their type.
+func (origin *MessageOrigin) name() string {
+	var name string
+	switch {
+	case origin.SenderUser != nil:
+		name = origin.SenderUser.Name()
+	case origin.SenderUserName != "":
+		name = origin.SenderUserName
+	case origin.SenderChat != nil:
+		name = origin.SenderChat.Title
+	case origin.Chat != nil:
+		name = origin.Chat.Title
+	}
+	if name == "" {
+		return origin.Type
+	}
+	return name
+}
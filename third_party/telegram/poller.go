@@ -0,0 +1,84 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// pollTimeout is how long each getUpdates call waits for an update.
+const pollTimeout = 30 * time.Second
+
+// pollRetryDelay is the pause after a failed getUpdates call.
+var pollRetryDelay = 5 * time.Second
+
+// getUpdatesRequest asks for the updates from Offset on, waiting up to
+// Timeout seconds for one to arrive.
+type getUpdatesRequest struct {
+	Offset         int64    `json:"offset,omitempty"`
+	Timeout        int      `json:"timeout"`
+	AllowedUpdates []string `json:"allowed_updates"`
+}
+
+// Poll receives updates with getUpdates long polling, starting at offset, and
+// passes each one to handle exactly as a webhook would receive it. Updates
+// are handled one at a time; after handle returns, the update is confirmed by
+// the next call. Poll returns nil once ctx is done, and an error if a webhook
+// is set, since Telegram refuses getUpdates then.
+func (t Telegram) Poll(ctx context.Context, offset int64, handle func(updateID int64, update []byte)) error {
+	for {
+		updates, err := getUpdates(ctx, offset)
+		if ctx.Err() != nil {
+			return nil
+		}
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusConflict {
+			return fmt.Errorf("%v (delete the webhook to poll for updates)", err)
+		}
+		if err != nil {
+			log.Printf("[Poll] %s, retrying in %s", err, pollRetryDelay)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(pollRetryDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			var header Update
+			if err := json.Unmarshal(update, &header); err != nil {
+				log.Printf("[Poll] Could not decode update: %v", err)
+				continue
+			}
+			handle(header.ID, update)
+			offset = header.ID + 1
+			if ctx.Err() != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// getUpdates returns the raw updates from offset on.
+func getUpdates(ctx context.Context, offset int64) ([]json.RawMessage, error) {
+	request := getUpdatesRequest{
+		Offset:         offset,
+		Timeout:        int(pollTimeout / time.Second),
+		AllowedUpdates: []string{"message", "edited_message"},
+	}
+	result, err := call(ctx, "getUpdates", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []json.RawMessage
+	if err := json.Unmarshal(result, &updates); err != nil {
+		return nil, fmt.Errorf("getUpdates: could not decode updates: %v", err)
+	}
+	return updates, nil
+}
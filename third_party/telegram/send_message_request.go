@@ -1,7 +1,50 @@
 package telegram
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 type SendMessageRequest struct {
 	ChatID           int64  `json:"chat_id"`
 	Text             string `json:"text"`
+	ParseMode        string `json:"parse_mode,omitempty"`
 	ReplyToMessageID *int64 `json:"reply_to_message_id,omitempty"`
 }
+
+type EditMessageTextRequest struct {
+	ChatID    int64  `json:"chat_id"`
+	MessageID int64  `json:"message_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+type DeleteMessageRequest struct {
+	ChatID    int64 `json:"chat_id"`
+	MessageID int64 `json:"message_id"`
+}
+
+type AnswerCallbackQueryRequest struct {
+	CallbackQueryID string `json:"callback_query_id"`
+	Text            string `json:"text,omitempty"`
+}
+
+// messageResponse is a Bot API response. Result is the sent or edited
+// message, or just true for methods such as deleteMessage.
+type messageResponse struct {
+	OK          bool            `json:"ok"`
+	ErrorCode   int             `json:"error_code,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+}
+
+// apiError is a Bot API call that returned ok=false.
+type apiError struct {
+	Method      string
+	Code        int
+	Description string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s failed: %s", e.Method, e.Description)
+}
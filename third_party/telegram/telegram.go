@@ -2,12 +2,18 @@ package telegram
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/morph/internal/botservice"
 )
@@ -15,9 +21,14 @@ import (
 type Telegram struct{}
 
 var baseURL string
+var fileURL string
+
+// maxFileSize is the largest file the Bot API lets bots download.
+const maxFileSize = 20 << 20
 
 func init() {
 	baseURL = "https://api.telegram.org/bot" + os.Getenv("MORPH_TELEGRAM_BOT_TOKEN")
+	fileURL = "https://api.telegram.org/file/bot" + os.Getenv("MORPH_TELEGRAM_BOT_TOKEN")
 }
 
 func (t Telegram) GetChatID() (int64, error) {
@@ -29,6 +40,31 @@ func (t Telegram) GetChatID() (int64, error) {
 	return chatID, nil
 }
 
+// self is the bot's own account, fetched with getMe the first time a group
+// message needs it.
+var (
+	selfMu sync.Mutex
+	self   *User
+)
+
+func botUser() (*User, error) {
+	selfMu.Lock()
+	defer selfMu.Unlock()
+	if self != nil {
+		return self, nil
+	}
+	result, err := call(context.Background(), "getMe", struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	var user User
+	if err := json.Unmarshal(result, &user); err != nil {
+		return nil, fmt.Errorf("getMe: could not decode the bot: %v", err)
+	}
+	self = &user
+	return self, nil
+}
+
 // Parse an incoming update
 func (t Telegram) Parse(body io.ReadCloser) *botservice.BotMessage {
 	var update Update
@@ -37,56 +73,238 @@ func (t Telegram) Parse(body io.ReadCloser) *botservice.BotMessage {
 		return nil
 	}
 
+	// An edited message is handled like a new one, marked as edited
+	var incoming = update.Message
+	var edited = false
+	if incoming == nil && update.EditedMessage != nil {
+		incoming = update.EditedMessage
+		edited = true
+	}
+
 	// Check if the message is valid
-	if update.Message == nil {
+	if incoming == nil {
 		log.Printf("[User] Invalid update: %d", update.ID)
 		return nil
 	}
 
-	var telegramUser = update.Message.From
+	var telegramUser = incoming.From
 
 	// Check if the user is valid
 	if telegramUser == nil || telegramUser.ID == 0 {
 		return nil
 	}
 
-	var input = update.Message.Text
+	var input = incoming.Text
+	if input == "" {
+		input = incoming.Caption
+	}
+	var fileID = incoming.imageFileID()
+	var command, commandBot, args = incoming.command()
+	var voiceFileID string
+	if incoming.Voice != nil {
+		voiceFileID = incoming.Voice.FileID
+	}
+
+	// In a group the bot only takes its commands and messages mentioning it
+	var group = incoming.Chat != nil && incoming.Chat.isGroup()
+	if group {
+		bot, err := botUser()
+		if err != nil {
+			log.Printf("[Parse] Could not tell whether a group message is for the bot: %s", err)
+			return nil
+		}
+		if command != "" {
+			if commandBot != "" && !strings.EqualFold(commandBot, bot.Username) {
+				return nil
+			}
+		} else {
+			var entities = incoming.Entities
+			if incoming.Text == "" {
+				entities = incoming.CaptionEntities
+			}
+			var mentioned bool
+			if input, mentioned = withoutMention(input, entities, bot); !mentioned {
+				return nil
+			}
+		}
+	}
 
 	// Check if the input is valid
-	if input == "" {
+	if input == "" && fileID == "" && voiceFileID == "" {
 		return nil
 	}
 
 	// Create a user from the telegram user
 	message := botservice.BotMessage{
-		MessageID: update.Message.ID,
-		UserID:    telegramUser.StringID(),
-		ChatID:    update.Message.Chat.ID,
-		Text:      input,
+		MessageID:   incoming.ID,
+		UserID:      telegramUser.StringID(),
+		UserName:    telegramUser.Name(),
+		ChatID:      incoming.Chat.ID,
+		Group:       group,
+		Text:        input,
+		FileID:      fileID,
+		VoiceFileID: voiceFileID,
+		Command:     command,
+		Args:        args,
+		Edited:      edited,
+	}
+	if incoming.Date != 0 {
+		message.Date = time.Unix(int64(incoming.Date), 0)
+	}
+	if origin := incoming.ForwardOrigin; origin != nil {
+		message.ForwardedFrom = origin.name()
+		message.ForwardedFromPerson = origin.person()
+		if origin.Date != 0 {
+			message.ForwardedAt = time.Unix(int64(origin.Date), 0)
+		}
 	}
 
 	return &message
 }
 
-func (t Telegram) SendMessage(chatID int64, text string, replyToMessageID *int64) {
-	var url string = baseURL + "/sendMessage"
-
+// SendMessage sends text to chatID, formatted per parseMode ("" for plain
+// text, "HTML" or "MarkdownV2"), and returns the ID of the sent message.
+func (t Telegram) SendMessage(chatID int64, text string, parseMode string, replyToMessageID *int64) (int64, error) {
 	message := SendMessageRequest{
 		ChatID:           chatID,
 		Text:             text,
+		ParseMode:        parseMode,
 		ReplyToMessageID: replyToMessageID,
 	}
+	sent, err := post("sendMessage", message)
+	if err != nil {
+		log.Printf("[SendMessage] %s", err)
+		return 0, err
+	}
+	return sent.ID, nil
+}
+
+// EditMessage replaces the text of a message the bot sent earlier.
+func (t Telegram) EditMessage(chatID int64, messageID int64, text string, parseMode string) error {
+	message := EditMessageTextRequest{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Text:      text,
+		ParseMode: parseMode,
+	}
+	_, err := post("editMessageText", message)
+	// Editing to the same text fails, but leaves the message as wanted.
+	if err != nil && strings.Contains(err.Error(), "message is not modified") {
+		return nil
+	}
+	if err != nil {
+		log.Printf("[EditMessage] %s", err)
+	}
+	return err
+}
+
+// DeleteMessage deletes a message. Bots can delete their own messages up to
+// 48 hours after sending them.
+func (t Telegram) DeleteMessage(chatID int64, messageID int64) error {
+	message := DeleteMessageRequest{
+		ChatID:    chatID,
+		MessageID: messageID,
+	}
+	if _, err := post("deleteMessage", message); err != nil {
+		log.Printf("[DeleteMessage] %s", err)
+		return err
+	}
+	return nil
+}
 
-	jsonData, err := json.Marshal(message)
+// AnswerCallback acknowledges a callback query from an inline button, which
+// Telegram expects within a few seconds of the press.
+func (t Telegram) AnswerCallback(callbackID string, text string) error {
+	answer := AnswerCallbackQueryRequest{
+		CallbackQueryID: callbackID,
+		Text:            text,
+	}
+	if _, err := post("answerCallbackQuery", answer); err != nil {
+		log.Printf("[AnswerCallback] %s", err)
+		return err
+	}
+	return nil
+}
+
+// post calls a Bot API method with a JSON body and returns the message in
+// the result, if the method returns one.
+func post(method string, body any) (*Message, error) {
+	result, err := call(context.Background(), method, body)
 	if err != nil {
-		log.Printf("[SendMessage] JSON parsing error: %s", err)
-		return
+		return nil, err
+	}
+
+	var message Message
+	if len(result) > 0 && result[0] == '{' {
+		if err := json.Unmarshal(result, &message); err != nil {
+			return nil, fmt.Errorf("%s: could not decode message: %v", method, err)
+		}
 	}
+	return &message, nil
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+// call calls a Bot API method with a JSON body and returns its raw result.
+// Cancelling ctx aborts the request.
+func call(ctx context.Context, method string, body any) (json.RawMessage, error) {
+	jsonData, err := json.Marshal(body)
 	if err != nil {
-		log.Printf("[SendMessage] POST error: %s", err)
-		return
+		return nil, fmt.Errorf("%s: JSON parsing error: %v", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/"+method, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("%s: could not create request: %v", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: POST error: %v", method, err)
 	}
 	defer resp.Body.Close()
+
+	var result messageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%s: could not decode response: %v", method, err)
+	}
+	if !result.OK {
+		return nil, &apiError{Method: method, Code: result.ErrorCode, Description: result.Description}
+	}
+	return result.Result, nil
+}
+
+// DownloadFile resolves fileID through getFile and downloads the file. The
+// media type is sniffed from the content, since photos carry none.
+func (t Telegram) DownloadFile(fileID string) (*botservice.File, error) {
+	resp, err := http.Get(baseURL + "/getFile?file_id=" + url.QueryEscape(fileID))
+	if err != nil {
+		return nil, fmt.Errorf("getFile request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result getFileResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("could not decode getFile response: %v", err)
+	}
+	if !result.OK || result.Result == nil || result.Result.FilePath == "" {
+		return nil, fmt.Errorf("getFile failed: %s", result.Description)
+	}
+	if result.Result.FileSize > maxFileSize {
+		return nil, fmt.Errorf("file is too large: %d bytes", result.Result.FileSize)
+	}
+
+	fileResp, err := http.Get(fileURL + "/" + result.Result.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("file download failed: %v", err)
+	}
+	defer fileResp.Body.Close()
+	if fileResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("file download returned status %d", fileResp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(fileResp.Body, maxFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("could not read file: %v", err)
+	}
+	log.Printf("[DownloadFile] Downloaded %s (%d bytes)", result.Result.FilePath, len(data))
+	return &botservice.File{Data: data, MediaType: http.DetectContentType(data)}, nil
 }
@@ -2,8 +2,14 @@ package telegram
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/morph/internal/botservice"
 )
@@ -27,14 +33,217 @@ func TestParse(t *testing.T) {
 					"chat": {
 						"id": 101112
 					},
-					"text": "hello world"
+					"text": "hello world",
+					"date": 1758290580
 				}
 			}`,
 			expected: &botservice.BotMessage{
 				MessageID: 456,
 				UserID:    "789",
+				UserName:  "testuser",
 				ChatID:    101112,
 				Text:      "hello world",
+				Date:      time.Unix(1758290580, 0),
+			},
+		},
+		{
+			name: "photo with caption",
+			input: `{
+				"update_id": 123,
+				"message": {
+					"message_id": 457,
+					"from": {"id": 789},
+					"chat": {"id": 101112},
+					"caption": "lunch",
+					"photo": [
+						{"file_id": "small", "width": 90, "height": 120},
+						{"file_id": "large", "width": 960, "height": 1280}
+					]
+				}
+			}`,
+			expected: &botservice.BotMessage{
+				MessageID: 457,
+				UserID:    "789",
+				ChatID:    101112,
+				Text:      "lunch",
+				FileID:    "large",
+			},
+		},
+		{
+			name: "image document",
+			input: `{
+				"update_id": 123,
+				"message": {
+					"message_id": 458,
+					"from": {"id": 789},
+					"chat": {"id": 101112},
+					"document": {"file_id": "doc", "mime_type": "image/jpeg"}
+				}
+			}`,
+			expected: &botservice.BotMessage{
+				MessageID: 458,
+				UserID:    "789",
+				ChatID:    101112,
+				FileID:    "doc",
+			},
+		},
+		{
+			name: "voice note",
+			input: `{
+				"update_id": 123,
+				"message": {
+					"message_id": 460,
+					"from": {"id": 789},
+					"chat": {"id": 101112},
+					"voice": {"file_id": "voice", "duration": 3, "mime_type": "audio/ogg"}
+				}
+			}`,
+			expected: &botservice.BotMessage{
+				MessageID:   460,
+				UserID:      "789",
+				ChatID:      101112,
+				VoiceFileID: "voice",
+			},
+		},
+		{
+			name: "non-image document",
+			input: `{
+				"update_id": 123,
+				"message": {
+					"message_id": 459,
+					"from": {"id": 789},
+					"chat": {"id": 101112},
+					"document": {"file_id": "doc", "mime_type": "application/zip"}
+				}
+			}`,
+			expected: nil,
+		},
+		{
+			name: "bot command addressed to the bot",
+			input: `{
+				"update_id": 123,
+				"message": {
+					"message_id": 460,
+					"from": {"id": 789},
+					"chat": {"id": 101112},
+					"text": "/Undo@MorphBot last one",
+					"entities": [{"type": "bot_command", "offset": 0, "length": 14}]
+				}
+			}`,
+			expected: &botservice.BotMessage{
+				MessageID: 460,
+				UserID:    "789",
+				ChatID:    101112,
+				Text:      "/Undo@MorphBot last one",
+				Command:   "undo",
+				Args:      "last one",
+			},
+		},
+		{
+			name: "forwarded from a bank's bot",
+			input: `{
+				"update_id": 123,
+				"message": {
+					"message_id": 461,
+					"from": {"id": 789},
+					"chat": {"id": 101112},
+					"text": "Оплата 120.00 UAH, картка *0451",
+					"date": 1758290580,
+					"forward_origin": {"type": "user", "date": 1758290000, "sender_user": {"id": 42, "is_bot": true, "first_name": "ПУМБ", "username": "pumb_bot"}}
+				}
+			}`,
+			expected: &botservice.BotMessage{
+				MessageID:     461,
+				UserID:        "789",
+				ChatID:        101112,
+				Text:          "Оплата 120.00 UAH, картка *0451",
+				Date:          time.Unix(1758290580, 0),
+				ForwardedFrom: "ПУМБ",
+				ForwardedAt:   time.Unix(1758290000, 0),
+			},
+		},
+		{
+			name: "forwarded from a channel",
+			input: `{
+				"update_id": 123,
+				"message": {
+					"message_id": 462,
+					"from": {"id": 789},
+					"chat": {"id": 101112},
+					"text": "Payment 15.00 EUR",
+					"forward_origin": {"type": "channel", "date": 1758290000, "chat": {"id": -100, "title": "BBVA alerts"}}
+				}
+			}`,
+			expected: &botservice.BotMessage{
+				MessageID:     462,
+				UserID:        "789",
+				ChatID:        101112,
+				Text:          "Payment 15.00 EUR",
+				ForwardedFrom: "BBVA alerts",
+				ForwardedAt:   time.Unix(1758290000, 0),
+			},
+		},
+		{
+			name: "forwarded from a person",
+			input: `{
+				"update_id": 123,
+				"message": {
+					"message_id": 463,
+					"from": {"id": 789},
+					"chat": {"id": 101112},
+					"text": "lunch 250",
+					"forward_origin": {"type": "user", "date": 1758290000, "sender_user": {"id": 43, "first_name": "Anna"}}
+				}
+			}`,
+			expected: &botservice.BotMessage{
+				MessageID:           463,
+				UserID:              "789",
+				ChatID:              101112,
+				Text:                "lunch 250",
+				ForwardedFrom:       "Anna",
+				ForwardedAt:         time.Unix(1758290000, 0),
+				ForwardedFromPerson: true,
+			},
+		},
+		{
+			name: "command not at the start is text",
+			input: `{
+				"update_id": 123,
+				"message": {
+					"message_id": 461,
+					"from": {"id": 789},
+					"chat": {"id": 101112},
+					"text": "☕️ coffee /help",
+					"entities": [{"type": "bot_command", "offset": 10, "length": 5}]
+				}
+			}`,
+			expected: &botservice.BotMessage{
+				MessageID: 461,
+				UserID:    "789",
+				ChatID:    101112,
+				Text:      "☕️ coffee /help",
+			},
+		},
+		{
+			name: "edited message",
+			input: `{
+				"update_id": 124,
+				"edited_message": {
+					"message_id": 456,
+					"from": {"id": 789},
+					"chat": {"id": 101112},
+					"text": "coffee 50",
+					"date": 1758290580,
+					"edit_date": 1758290640
+				}
+			}`,
+			expected: &botservice.BotMessage{
+				MessageID: 456,
+				UserID:    "789",
+				ChatID:    101112,
+				Text:      "coffee 50",
+				Date:      time.Unix(1758290580, 0),
+				Edited:    true,
 			},
 		},
 		{
@@ -91,10 +300,331 @@ func TestParse(t *testing.T) {
 				if tt.expected.MessageID != result.MessageID ||
 					tt.expected.UserID != result.UserID ||
 					tt.expected.ChatID != result.ChatID ||
-					tt.expected.Text != result.Text {
+					tt.expected.Text != result.Text ||
+					tt.expected.FileID != result.FileID ||
+					tt.expected.VoiceFileID != result.VoiceFileID ||
+					tt.expected.Command != result.Command ||
+					tt.expected.Args != result.Args ||
+					tt.expected.Edited != result.Edited ||
+					tt.expected.UserName != result.UserName ||
+					tt.expected.Group != result.Group ||
+					tt.expected.ForwardedFrom != result.ForwardedFrom ||
+					tt.expected.ForwardedFromPerson != result.ForwardedFromPerson ||
+					!tt.expected.ForwardedAt.Equal(result.ForwardedAt) ||
+					!tt.expected.Date.Equal(result.Date) {
 					t.Errorf("expected %v, got %v", tt.expected, result)
 				}
 			}
 		})
 	}
 }
+
+func TestParse_Group(t *testing.T) {
+	getMeCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot/getMe" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		getMeCalls++
+		fmt.Fprint(w, `{"ok":true,"result":{"id":99,"is_bot":true,"first_name":"Morph","username":"MorphBot"}}`)
+	}))
+	defer server.Close()
+	previousBase := baseURL
+	baseURL = server.URL + "/bot"
+	defer func() { baseURL = previousBase; self = nil }()
+
+	update := func(text string, entities string) string {
+		return `{"update_id": 1, "message": {"message_id": 70, "from": {"id": 789, "first_name": "Anna", "last_name": "Koval"},
+			"chat": {"id": -500, "type": "supergroup", "title": "Family"}, "text": "` + text + `", "entities": [` + entities + `]}}`
+	}
+	tests := []struct {
+		name  string
+		input string
+		text  string
+		cmd   string
+	}{
+		{"mention", update("@morphbot coffee 65", `{"type": "mention", "offset": 0, "length": 9}`), "coffee 65", ""},
+		{"mention in the middle", update("taxi @MorphBot 230", `{"type": "mention", "offset": 5, "length": 9}`), "taxi 230", ""},
+		{"text mention", update("Morph cake 120", `{"type": "text_mention", "offset": 0, "length": 5, "user": {"id": 99}}`), "cake 120", ""},
+		{"command", update("/undo", `{"type": "bot_command", "offset": 0, "length": 5}`), "/undo", "undo"},
+		{"command for the bot", update("/undo@MorphBot", `{"type": "bot_command", "offset": 0, "length": 14}`), "/undo@MorphBot", "undo"},
+		{"command for another bot", update("/undo@OtherBot", `{"type": "bot_command", "offset": 0, "length": 14}`), "", ""},
+		{"mention of someone else", update("@max coffee 65", `{"type": "mention", "offset": 0, "length": 4}`), "", ""},
+		{"plain message", update("coffee 65", ``), "", ""},
+		{"only the mention", update("@MorphBot", `{"type": "mention", "offset": 0, "length": 9}`), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Telegram{}.Parse(io.NopCloser(strings.NewReader(tt.input)))
+			if tt.text == "" {
+				if result != nil {
+					t.Errorf("expected the message to be ignored, got %+v", result)
+				}
+				return
+			}
+			if result == nil {
+				t.Fatal("expected a message, got nil")
+			}
+			if result.Text != tt.text || result.Command != tt.cmd || !result.Group || result.UserName != "Anna Koval" || result.ChatID != -500 {
+				t.Errorf("got %+v, want text %q and command %q from Anna in the group", result, tt.text, tt.cmd)
+			}
+		})
+	}
+	if getMeCalls != 1 {
+		t.Errorf("getMe calls = %d, want 1", getMeCalls)
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nreceipt")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bot/getFile":
+			if r.URL.Query().Get("file_id") != "large" {
+				t.Errorf("Expected file_id large, got %q", r.URL.Query().Get("file_id"))
+			}
+			w.Write([]byte(`{"ok":true,"result":{"file_id":"large","file_size":15,"file_path":"photos/file_1.png"}}`))
+		case "/file/bot/photos/file_1.png":
+			w.Write(png)
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	previousBase, previousFile := baseURL, fileURL
+	baseURL, fileURL = server.URL+"/bot", server.URL+"/file/bot"
+	defer func() { baseURL, fileURL = previousBase, previousFile }()
+
+	file, err := Telegram{}.DownloadFile("large")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !bytes.Equal(file.Data, png) || file.MediaType != "image/png" {
+		t.Errorf("Unexpected file: %s %q", file.MediaType, file.Data)
+	}
+}
+
+func TestDownloadFile_GetFileError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"description":"Bad Request: invalid file_id"}`))
+	}))
+	defer server.Close()
+
+	previousBase := baseURL
+	baseURL = server.URL + "/bot"
+	defer func() { baseURL = previousBase }()
+
+	if _, err := (Telegram{}).DownloadFile("missing"); err == nil {
+		t.Error("Expected error for invalid file_id")
+	}
+}
+
+func TestSendMessage_ReturnsTheSentMessageID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot/sendMessage" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"ok":true,"result":{"message_id":991,"chat":{"id":101112},"date":1758290580,"text":"hi"}}`))
+	}))
+	defer server.Close()
+
+	previousBase := baseURL
+	baseURL = server.URL + "/bot"
+	defer func() { baseURL = previousBase }()
+
+	id, err := Telegram{}.SendMessage(101112, "hi", "", nil)
+	if err != nil || id != 991 {
+		t.Errorf("Expected message 991, got %d, %v", id, err)
+	}
+}
+
+func TestSendMessage_ParseMode(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Write([]byte(`{"ok":true,"result":{"message_id":991,"chat":{"id":101112}}}`))
+	}))
+	defer server.Close()
+
+	previousBase := baseURL
+	baseURL = server.URL + "/bot"
+	defer func() { baseURL = previousBase }()
+
+	Telegram{}.SendMessage(101112, "<b>hi</b>", "HTML", nil)
+	Telegram{}.EditMessage(101112, 991, "*hi*", "MarkdownV2")
+	Telegram{}.SendMessage(101112, "hi", "", nil)
+
+	want := []string{
+		`{"chat_id":101112,"text":"\u003cb\u003ehi\u003c/b\u003e","parse_mode":"HTML"}`,
+		`{"chat_id":101112,"message_id":991,"text":"*hi*","parse_mode":"MarkdownV2"}`,
+		`{"chat_id":101112,"text":"hi"}`,
+	}
+	for i := range want {
+		if i >= len(bodies) || bodies[i] != want[i] {
+			t.Errorf("Unexpected requests %v", bodies)
+			break
+		}
+	}
+}
+
+func TestEditMessage(t *testing.T) {
+	var description string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot/editMessageText" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		body, _ = io.ReadAll(r.Body)
+		if description != "" {
+			w.Write([]byte(`{"ok":false,"description":"` + description + `"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{"message_id":991,"chat":{"id":101112},"text":"coffee 50"}}`))
+	}))
+	defer server.Close()
+
+	previousBase := baseURL
+	baseURL = server.URL + "/bot"
+	defer func() { baseURL = previousBase }()
+
+	if err := (Telegram{}).EditMessage(101112, 991, "coffee 50", ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(body) != `{"chat_id":101112,"message_id":991,"text":"coffee 50"}` {
+		t.Errorf("Unexpected request %s", body)
+	}
+
+	description = "Bad Request: message is not modified: specified new message content and reply markup are exactly the same"
+	if err := (Telegram{}).EditMessage(101112, 991, "coffee 50", ""); err != nil {
+		t.Errorf("Expected an unchanged message to count as edited, got %v", err)
+	}
+
+	description = "Bad Request: message to edit not found"
+	if err := (Telegram{}).EditMessage(101112, 991, "coffee 50", ""); err == nil {
+		t.Error("Expected an error for a missing message")
+	}
+}
+
+func TestDeleteMessageAndAnswerCallback(t *testing.T) {
+	requests := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests[r.URL.Path] = string(body)
+		if r.URL.Path == "/bot/deleteMessage" && requests["fail"] != "" {
+			w.Write([]byte(`{"ok":false,"description":"Bad Request: message can't be deleted"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer server.Close()
+
+	previousBase := baseURL
+	baseURL = server.URL + "/bot"
+	defer func() { baseURL = previousBase }()
+
+	if err := (Telegram{}).DeleteMessage(101112, 991); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if requests["/bot/deleteMessage"] != `{"chat_id":101112,"message_id":991}` {
+		t.Errorf("Unexpected deleteMessage request %s", requests["/bot/deleteMessage"])
+	}
+
+	if err := (Telegram{}).AnswerCallback("cb-1", "Saved"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if requests["/bot/answerCallbackQuery"] != `{"callback_query_id":"cb-1","text":"Saved"}` {
+		t.Errorf("Unexpected answerCallbackQuery request %s", requests["/bot/answerCallbackQuery"])
+	}
+
+	requests["fail"] = "yes"
+	if err := (Telegram{}).DeleteMessage(101112, 991); err == nil {
+		t.Error("Expected an error when the message can't be deleted")
+	}
+}
+
+func TestPoll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot/getUpdates" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, string(body))
+		switch len(requests) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`<html>Bad Gateway</html>`))
+		case 2:
+			w.Write([]byte(`{"ok":true,"result":[{"update_id":5,"message":{"message_id":1,"text":"coffee 5"}},{"update_id":6,"edited_message":{"message_id":1,"text":"coffee 50"}}]}`))
+		default:
+			cancel()
+			w.Write([]byte(`{"ok":true,"result":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	previousBase, previousDelay := baseURL, pollRetryDelay
+	baseURL, pollRetryDelay = server.URL+"/bot", time.Millisecond
+	defer func() { baseURL, pollRetryDelay = previousBase, previousDelay }()
+
+	var handled []string
+	err := Telegram{}.Poll(ctx, 5, func(updateID int64, update []byte) {
+		handled = append(handled, fmt.Sprintf("%d %s", updateID, update))
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(handled) != 2 || !strings.HasPrefix(handled[0], `5 {"update_id":5,"message"`) || !strings.HasPrefix(handled[1], `6 {"update_id":6,"edited_message"`) {
+		t.Errorf("Unexpected updates %v", handled)
+	}
+	want := []string{
+		`{"offset":5,"timeout":30,"allowed_updates":["message","edited_message"]}`,
+		`{"offset":5,"timeout":30,"allowed_updates":["message","edited_message"]}`,
+		`{"offset":7,"timeout":30,"allowed_updates":["message","edited_message"]}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected requests %v", requests)
+	}
+}
+
+func TestPoll_WebhookConflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"ok":false,"error_code":409,"description":"Conflict: can't use getUpdates method while webhook is active; use deleteWebhook to delete the webhook first"}`))
+	}))
+	defer server.Close()
+
+	previousBase := baseURL
+	baseURL = server.URL + "/bot"
+	defer func() { baseURL = previousBase }()
+
+	err := Telegram{}.Poll(context.Background(), 0, func(int64, []byte) {
+		t.Error("Expected no updates")
+	})
+	if err == nil || !strings.Contains(err.Error(), "webhook is active") {
+		t.Errorf("Expected the webhook conflict, got %v", err)
+	}
+}
+
+func TestUserName(t *testing.T) {
+	tests := []struct {
+		user User
+		want string
+	}{
+		{User{ID: 1, FirstName: "Anna", LastName: "Koval", Username: "anna_k"}, "Anna Koval"},
+		{User{ID: 2, FirstName: "Ostap"}, "Ostap"},
+		{User{ID: 3, Username: "max"}, "max"},
+	}
+	for _, tt := range tests {
+		if got := tt.user.Name(); got != tt.want {
+			t.Errorf("Name() of user %d = %q, want %q", tt.user.ID, got, tt.want)
+		}
+	}
+}
@@ -3,10 +3,24 @@ package telegram
 import "fmt"
 
 type User struct {
-	ID    int64 `json:"id"`
-	IsBot bool  `json:"is_bot,omitempty"`
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
 }
 
 func (user *User) StringID() string {
 	return fmt.Sprintf("%d", user.ID)
 }
+
+// Name is the user's full name, or their username without one.
+func (user *User) Name() string {
+	if user.FirstName == "" {
+		return user.Username
+	}
+	if user.LastName == "" {
+		return user.FirstName
+	}
+	return user.FirstName + " " + user.LastName
+}